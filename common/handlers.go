@@ -1,11 +1,20 @@
 package common
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libreria/middlewares"
+	"libreria/models"
 	"libreria/requests"
+	"libreria/utils"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 func Get[T any](ops Operations[T]) gin.HandlerFunc {
@@ -27,6 +36,7 @@ func GetByID[T any](ops Operations[T]) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Entidad no encontrada"})
 			return
 		}
+		setETag(c, model)
 		c.JSON(http.StatusOK, model)
 	}
 }
@@ -138,36 +148,170 @@ func Update[T any, R requests.MapperRequest[T]](ops Operations[T]) gin.HandlerFu
 			return
 		}
 
-		if validator, ok := any(request).(requests.ValidateOnUpdate[T]); ok {
-			if err := validator.ValidateUpdate(ops.(*GormOperations[T]).db, existing); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
+		saveUpdate(c, ops, existing, request)
+	}
+}
+
+// Patch aplica un JSON merge patch (RFC 7386) sobre el registro: los campos
+// que no vienen quedan como están y null los vacía. El resultado se valida
+// campo por campo y pasa por los mismos controles que un PUT.
+func Patch[T any, R requests.MapperRequest[T]](ops Operations[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		existing, err := ops.FindByID(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Entidad no encontrada"})
+			return
 		}
 
-		model, err := mapper(request, existing)
+		patch, err := c.GetRawData()
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		updatedModel, err := ops.Update(model)
+		request, err := patchRequest[R](existing, patch)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := binding.Validator.ValidateStruct(request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		saveUpdate(c, ops, existing, request)
+	}
+}
+
+// patchRequest arma el request que resulta de aplicar el patch al registro.
+// El documento base es el registro visto como request, así que solo se
+// pueden cambiar los campos que acepta un PUT.
+func patchRequest[R any](existing any, patch []byte) (R, error) {
+	var request R
+	current, err := json.Marshal(existing)
+	if err != nil {
+		return request, err
+	}
+	if err := json.Unmarshal(current, &request); err != nil {
+		return request, err
+	}
+	document, err := json.Marshal(request)
+	if err != nil {
+		return request, err
+	}
+
+	merged, err := utils.MergePatch(document, patch)
+	if err != nil {
+		return request, err
+	}
+
+	var patched R
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return patched, err
+	}
+	return patched, nil
+}
+
+// saveUpdate valida y guarda el request sobre existing. Con If-Match, solo
+// guarda si el ETag coincide con la versión leída; si no, o si otro la cambió
+// mientras tanto, responde 412.
+func saveUpdate[T any, R requests.MapperRequest[T]](c *gin.Context, ops Operations[T], existing T, request R) {
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && !matchesETag(ifMatch, existing) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": models.ErrVersionConflict.Error()})
+		return
+	}
+
+	if validator, ok := any(request).(requests.ValidateOnUpdate[T]); ok {
+		if err := validator.ValidateUpdate(ops.(*GormOperations[T]).db, existing); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+	}
+
+	model, err := mapper(request, existing)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedModel, err := ops.Update(model)
+	if errors.Is(err, models.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, updatedModel)
+	c.JSON(http.StatusOK, updatedModel)
+}
+
+// etag es la versión del modelo entre comillas, ej: "3"; vacío si el modelo
+// no tiene versión.
+func etag(model any) string {
+	versioned, ok := model.(interface{ CurrentVersion() uint })
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%q", strconv.FormatUint(uint64(versioned.CurrentVersion()), 10))
+}
 
-		c.JSON(http.StatusOK, updatedModel)
+func setETag(c *gin.Context, model any) {
+	if tag := etag(model); tag != "" {
+		c.Header("ETag", tag)
 	}
 }
 
+// matchesETag compara If-Match, que puede traer varios ETags o "*", con la
+// versión actual. Los modelos sin versión siempre coinciden.
+func matchesETag(ifMatch string, model any) bool {
+	current := etag(model)
+	if current == "" {
+		return true
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete borra el registro si nada lo referencia. Con ?reassign_to=ID pasa
+// antes las referencias que lo admiten a ese registro.
 func Delete[T any](ops Operations[T]) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		if err := ops.Delete(id); err != nil {
+		if _, err := ops.FindByID(id); err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "No encontrado"})
 			return
 		}
+
+		var err error
+		if target := c.Query("reassign_to"); target != "" {
+			err = ops.DeleteReassigning(id, target)
+		} else {
+			err = ops.Delete(id)
+		}
+
+		var referenceErr *ReferenceError
+		switch {
+		case errors.As(err, &referenceErr):
+			c.JSON(http.StatusConflict, gin.H{"error": referenceErr.Error(), "references": referenceErr.References})
+			return
+		case errors.Is(err, ErrInvalidReassignTarget):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{"message": "Eliminado con éxito"})
 	}
 }
@@ -182,3 +326,52 @@ func mapper[T any, R requests.MapperRequest[T]](req R, existing ...T) (T, error)
 func mapperArray[T any, R requests.MapperArrayRequest[T]](req R) ([]T, error) {
 	return req.ToArrayModel()
 }
+
+func GetDeleted[T any](ops Operations[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		models, err := ops.FindDeleted()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, models)
+	}
+}
+
+// Restore saca el registro de la papelera; si ya no es válido (ej: otro tomó
+// su nombre) responde 400 con el motivo.
+func Restore[T any](ops Operations[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		model, err := ops.Restore(c.Param("id"))
+		switch {
+		case errors.Is(err, ErrNotInTrash):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, model)
+	}
+}
+
+// Merge fusiona en el registro de la ruta los duplicados del cuerpo. La
+// auditoría se guarda junto con la fusión, no desde el middleware.
+func Merge[T any](ops Operations[T]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request requests.MergeRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := ops.Merge(c.Param("id"), request.DuplicateIDs, middlewares.NewAuditLog(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		middlewares.MarkAudited(c)
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,285 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"libreria/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testModel struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	models.Versioned
+}
+
+type testRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func (r testRequest) ToModel() (testModel, error) {
+	return testModel{Name: r.Name, Description: r.Description}, nil
+}
+
+func (r testRequest) UpdateModel(existing testModel) (testModel, error) {
+	existing.Name = r.Name
+	existing.Description = r.Description
+	return existing, nil
+}
+
+// fakeOperations guarda un único registro en memoria. Los métodos que las
+// pruebas no usan quedan sin implementar.
+type fakeOperations struct {
+	Operations[testModel]
+	model     testModel
+	updated   *testModel
+	updateErr error
+
+	deleteErr  error
+	deleted    string
+	reassigned string // registro al que se pasaron las referencias
+}
+
+func (f *fakeOperations) FindByID(id string) (testModel, error) {
+	if id != "1" {
+		return testModel{}, errors.New("no encontrado")
+	}
+	return f.model, nil
+}
+
+func (f *fakeOperations) Update(model testModel) (testModel, error) {
+	if f.updateErr != nil {
+		return testModel{}, f.updateErr
+	}
+	model.Version++
+	f.updated = &model
+	return model, nil
+}
+
+func (f *fakeOperations) Delete(id string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = id
+	return nil
+}
+
+func (f *fakeOperations) DeleteReassigning(id, targetID string) error {
+	if targetID != "2" {
+		return ErrInvalidReassignTarget
+	}
+	f.reassigned = targetID
+	f.deleted = id
+	return nil
+}
+
+func newTestRouter(ops Operations[testModel]) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/items/:id", Update[testModel, testRequest](ops))
+	router.PATCH("/items/:id", Patch[testModel, testRequest](ops))
+	router.DELETE("/items/:id", Delete[testModel](ops))
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestPatchAppliesMergePatch(t *testing.T) {
+	ops := &fakeOperations{model: testModel{ID: 1, Name: "Lapicera", Description: "Azul", Versioned: models.Versioned{Version: 1}}}
+	router := newTestRouter(ops)
+
+	// Solo cambia lo que trae el patch; null vacía el campo
+	recorder := serve(router, http.MethodPatch, "/items/1", `{"description":null}`, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s, se esperaba 200", recorder.Code, recorder.Body)
+	}
+	if ops.updated == nil || ops.updated.Name != "Lapicera" || ops.updated.Description != "" {
+		t.Errorf("se guardó %+v, se esperaba el nombre sin cambios y la descripción vacía", ops.updated)
+	}
+}
+
+func TestPatchValidatesResult(t *testing.T) {
+	cases := map[string]string{
+		"campo obligatorio en null": `{"name":null}`,
+		"campo que no acepta PUT":   `{"version":7}`,
+		"patch que no es un objeto": `["name"]`,
+	}
+	for name, patch := range cases {
+		ops := &fakeOperations{model: testModel{ID: 1, Name: "Lapicera"}}
+		recorder := serve(newTestRouter(ops), http.MethodPatch, "/items/1", patch, nil)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: PATCH = %d, se esperaba 400", name, recorder.Code)
+		}
+		if ops.updated != nil {
+			t.Errorf("%s: no se tenía que guardar nada", name)
+		}
+	}
+}
+
+func TestPatchNotFound(t *testing.T) {
+	recorder := serve(newTestRouter(&fakeOperations{}), http.MethodPatch, "/items/2", `{"name":"x"}`, nil)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("PATCH = %d, se esperaba 404", recorder.Code)
+	}
+}
+
+func TestUpdateChecksIfMatch(t *testing.T) {
+	cases := []struct {
+		ifMatch string
+		status  int
+	}{
+		{"", http.StatusOK},
+		{`"3"`, http.StatusOK},
+		{`"1", "3"`, http.StatusOK},
+		{"*", http.StatusOK},
+		{`"2"`, http.StatusPreconditionFailed},
+		{`3`, http.StatusPreconditionFailed},
+	}
+	for _, c := range cases {
+		ops := &fakeOperations{model: testModel{ID: 1, Name: "Lapicera", Versioned: models.Versioned{Version: 3}}}
+		headers := map[string]string{}
+		if c.ifMatch != "" {
+			headers["If-Match"] = c.ifMatch
+		}
+
+		recorder := serve(newTestRouter(ops), http.MethodPut, "/items/1", `{"name":"Lápiz"}`, headers)
+		if recorder.Code != c.status {
+			t.Errorf("If-Match %s: PUT = %d, se esperaba %d", c.ifMatch, recorder.Code, c.status)
+			continue
+		}
+		if c.status != http.StatusOK {
+			if ops.updated != nil {
+				t.Errorf("If-Match %s: no se tenía que guardar nada", c.ifMatch)
+			}
+			continue
+		}
+		if etag := recorder.Header().Get("ETag"); etag != `"4"` {
+			t.Errorf("If-Match %s: ETag = %s, se esperaba la nueva versión \"4\"", c.ifMatch, etag)
+		}
+	}
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	ops := &fakeOperations{model: testModel{ID: 1, Name: "Lapicera", Versioned: models.Versioned{Version: 3}}, updateErr: models.ErrVersionConflict}
+
+	// Otro guardó entre la lectura y la escritura
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		recorder := serve(newTestRouter(ops), method, "/items/1", `{"name":"Lápiz"}`, map[string]string{"If-Match": `"3"`})
+		if recorder.Code != http.StatusPreconditionFailed {
+			t.Errorf("%s = %d, se esperaba 412", method, recorder.Code)
+		}
+	}
+}
+
+func TestPatchChecksIfMatch(t *testing.T) {
+	ops := &fakeOperations{model: testModel{ID: 1, Name: "Lapicera", Versioned: models.Versioned{Version: 3}}}
+	recorder := serve(newTestRouter(ops), http.MethodPatch, "/items/1", `{"name":"Lápiz"}`, map[string]string{"If-Match": `"2"`})
+	if recorder.Code != http.StatusPreconditionFailed || ops.updated != nil {
+		t.Errorf("PATCH = %d, se esperaba 412 sin guardar", recorder.Code)
+	}
+}
+
+func TestGetByIDSetsETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items/:id", GetByID[testModel](&fakeOperations{model: testModel{ID: 1, Versioned: models.Versioned{Version: 5}}}))
+
+	recorder := serve(router, http.MethodGet, "/items/1", "", nil)
+	if etag := recorder.Header().Get("ETag"); etag != `"5"` {
+		t.Errorf("ETag = %s, se esperaba \"5\"", etag)
+	}
+}
+
+func decodeBody(t *testing.T, recorder *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("respuesta inválida %s: %v", recorder.Body, err)
+	}
+	return body
+}
+
+func TestDeleteWithReferences(t *testing.T) {
+	ops := &fakeOperations{
+		model: testModel{ID: 1},
+		deleteErr: &ReferenceError{References: []BlockingReference{
+			{Name: "productos", Count: 3, Reassignable: true},
+			{Name: "promociones", Count: 1},
+		}},
+	}
+
+	recorder := serve(newTestRouter(ops), http.MethodDelete, "/items/1", "", nil)
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("DELETE = %d, se esperaba 409", recorder.Code)
+	}
+	body := decodeBody(t, recorder)
+	if body["error"] != "no se puede eliminar porque lo usan: 3 productos, 1 promociones" {
+		t.Errorf("error = %v", body["error"])
+	}
+	references, _ := body["references"].([]interface{})
+	if len(references) != 2 {
+		t.Fatalf("references = %v, se esperaban las 2 que bloquean", body["references"])
+	}
+	first := references[0].(map[string]interface{})
+	if first["name"] != "productos" || first["count"] != float64(3) || first["reassignable"] != true {
+		t.Errorf("references[0] = %v", first)
+	}
+}
+
+func TestDeleteReassigning(t *testing.T) {
+	ops := &fakeOperations{model: testModel{ID: 1}}
+
+	recorder := serve(newTestRouter(ops), http.MethodDelete, "/items/1?reassign_to=2", "", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d %s, se esperaba 200", recorder.Code, recorder.Body)
+	}
+	if ops.deleted != "1" || ops.reassigned != "2" {
+		t.Errorf("se borró %q reasignando a %q, se esperaba borrar 1 reasignando a 2", ops.deleted, ops.reassigned)
+	}
+
+	recorder = serve(newTestRouter(&fakeOperations{model: testModel{ID: 1}}), http.MethodDelete, "/items/1?reassign_to=9", "", nil)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("DELETE reasignando a un registro inexistente = %d, se esperaba 400", recorder.Code)
+	}
+
+	recorder = serve(newTestRouter(&fakeOperations{}), http.MethodDelete, "/items/5", "", nil)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("DELETE de un registro inexistente = %d, se esperaba 404", recorder.Code)
+	}
+}
+
+func TestDeleteReassigningToItself(t *testing.T) {
+	// Se rechaza antes de abrir la transacción, así que no hace falta base
+	ops := NewGormOperations[testModel](nil)
+	if err := ops.DeleteReassigning("1", "1"); !errors.Is(err, ErrInvalidReassignTarget) {
+		t.Errorf("DeleteReassigning al mismo registro = %v, se esperaba ErrInvalidReassignTarget", err)
+	}
+}
+
+func TestReassignValuesBumpsVersion(t *testing.T) {
+	versioned := reassignValues(Reference{Model: &models.Product{}, Column: "brand_id"}, "2")
+	if versioned["brand_id"] != "2" || versioned["version"] == nil {
+		t.Errorf("reassignValues = %v, se esperaba la columna y la versión siguiente", versioned)
+	}
+
+	plain := reassignValues(Reference{Model: &models.ProductBarcode{}, Column: "product_id"}, "2")
+	if _, ok := plain["version"]; ok {
+		t.Errorf("reassignValues = %v, un modelo sin versión no la incrementa", plain)
+	}
+}
@@ -1,8 +1,14 @@
 package common
 
 import (
+	"errors"
 	"fmt"
+	"libreria/models"
+	"log"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,11 +28,87 @@ type Operations[T any] interface {
 	CreateMany(model []T) error
 	Update(model T) (T, error)
 	Delete(id string) error
+	DeleteReassigning(id, targetID string) error
 	Pluck(field string) ([]string, error)
+	FindDeleted() ([]T, error)
+	Restore(id string) (T, error)
+	PurgeDeleted(before time.Time) (PurgeResult, error)
+	Merge(survivorID string, duplicateIDs []uint, audit models.AuditLog) (MergeResult[T], error)
+}
+
+// Reference describe registros de otra tabla que apuntan al modelo y que
+// impiden borrarlo mientras existan.
+type Reference struct {
+	Name         string // para mostrar, ej: "productos"
+	Model        any    // ej: &models.Product{}
+	Column       string // columna que guarda el ID, ej: "brand_id"
+	Filter       string // condición adicional, ej: "scope = 'brand'"
+	Reassignable bool   // si se pueden pasar a otro registro antes de borrar
+	MergeOnly    bool   // solo se mueve al fusionar; no impide borrar
+	// Unique son las columnas de un índice único que incluye Column. Al
+	// fusionar se descartan las filas del duplicado que chocarían con las
+	// del registro que queda, ej: {"product_id", "supplier_id"}.
+	Unique []string
+}
+
+type BlockingReference struct {
+	Name         string `json:"name"`
+	Count        int64  `json:"count"`
+	Reassignable bool   `json:"reassignable"`
+}
+
+// ReferenceError indica que el borrado se rechazó por registros que todavía
+// apuntan al modelo.
+type ReferenceError struct {
+	References []BlockingReference
+}
+
+func (e *ReferenceError) Error() string {
+	parts := make([]string, 0, len(e.References))
+	for _, reference := range e.References {
+		parts = append(parts, fmt.Sprintf("%d %s", reference.Count, reference.Name))
+	}
+	return "no se puede eliminar porque lo usan: " + strings.Join(parts, ", ")
+}
+
+var ErrInvalidReassignTarget = errors.New("el registro al que se reasigna no existe o es el mismo que se elimina")
+
+var ErrNotInTrash = errors.New("el registro no está en la papelera")
+
+// RestoreValidator controla que un registro borrado se pueda restaurar, ej:
+// que su nombre no lo haya tomado otro mientras estaba en la papelera.
+type RestoreValidator[T any] func(db *gorm.DB, model T) error
+
+// PurgeResult resume una purga: los registros que se quitaron y los que se
+// dejaron porque otras tablas todavía los usan.
+type PurgeResult struct {
+	Purged  int64 `json:"purged"`
+	Skipped int64 `json:"skipped"`
+}
+
+var ErrInvalidMerge = errors.New("los registros a fusionar deben existir y ser distintos del que queda")
+
+// MergeValidator controla que los duplicados se puedan fusionar en survivor.
+type MergeValidator[T any] func(db *gorm.DB, survivor T, duplicates []T) error
+
+type MovedReference struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// MergeResult es el registro que quedó después de fusionar, con lo que se le
+// pasó de los duplicados y una descripción para la auditoría.
+type MergeResult[T any] struct {
+	Survivor T                `json:"survivor"`
+	Moved    []MovedReference `json:"moved"`
+	Detail   string           `json:"detail"`
 }
 
 type GormOperations[T any] struct {
-	db *gorm.DB
+	db              *gorm.DB
+	references      []Reference
+	validateRestore RestoreValidator[T]
+	validateMerge   MergeValidator[T]
 }
 
 func NewGormOperations[T any](db *gorm.DB) *GormOperations[T] {
@@ -71,13 +153,310 @@ func (ops *GormOperations[T]) CreateMany(model []T) error {
 }
 
 func (ops *GormOperations[T]) Update(model T) (T, error) {
+	// Los modelos con versión solo se guardan si nadie los cambió desde que se leyeron
+	if versioned, ok := any(&model).(models.VersionedModel); ok {
+		err := models.UpdateVersioned(ops.db, versioned)
+		return model, err
+	}
 	err := ops.db.Save(&model).Error
 	return model, err
 }
 
+// WithReferences registra las tablas que se controlan antes de borrar.
+func (ops *GormOperations[T]) WithReferences(references ...Reference) *GormOperations[T] {
+	ops.references = append(ops.references, references...)
+	return ops
+}
+
+// Delete borra el registro solo si ninguna referencia lo usa; si no, devuelve
+// un *ReferenceError con la cantidad de registros de cada una.
 func (ops *GormOperations[T]) Delete(id string) error {
+	return ops.db.Transaction(func(tx *gorm.DB) error {
+		return ops.deleteUnreferenced(tx, id)
+	})
+}
+
+// DeleteReassigning pasa al registro targetID las referencias que lo admiten
+// y después borra el registro, todo en una transacción.
+func (ops *GormOperations[T]) DeleteReassigning(id, targetID string) error {
+	if id == targetID {
+		return ErrInvalidReassignTarget
+	}
+
+	return ops.db.Transaction(func(tx *gorm.DB) error {
+		var target T
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return ErrInvalidReassignTarget
+		}
+
+		for _, reference := range ops.references {
+			if !reference.Reassignable {
+				continue
+			}
+			query := tx.Model(reference.Model).Where(reference.Column+" = ?", id)
+			if reference.Filter != "" {
+				query = query.Where(reference.Filter)
+			}
+			if err := query.Updates(reassignValues(reference, targetID)).Error; err != nil {
+				return fmt.Errorf("error al reasignar %s: %v", reference.Name, err)
+			}
+		}
+
+		return ops.deleteUnreferenced(tx, id)
+	})
+}
+
+func (ops *GormOperations[T]) deleteUnreferenced(tx *gorm.DB, id string) error {
+	blocking, err := ops.blockingReferences(tx, id)
+	if err != nil {
+		return err
+	}
+	if len(blocking) > 0 {
+		return &ReferenceError{References: blocking}
+	}
+
 	var model T
-	return ops.db.Delete(&model, id).Error
+	return tx.Delete(&model, id).Error
+}
+
+func (ops *GormOperations[T]) blockingReferences(tx *gorm.DB, id string) ([]BlockingReference, error) {
+	var blocking []BlockingReference
+	for _, reference := range ops.references {
+		if reference.MergeOnly {
+			continue
+		}
+		var count int64
+		query := tx.Model(reference.Model).Where(reference.Column+" = ?", id)
+		if reference.Filter != "" {
+			query = query.Where(reference.Filter)
+		}
+		if err := query.Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			blocking = append(blocking, BlockingReference{Name: reference.Name, Count: count, Reassignable: reference.Reassignable})
+		}
+	}
+	return blocking, nil
+}
+
+// WithRestoreValidation registra el control que se hace antes de restaurar.
+func (ops *GormOperations[T]) WithRestoreValidation(validator RestoreValidator[T]) *GormOperations[T] {
+	ops.validateRestore = validator
+	return ops
+}
+
+// FindDeleted lista los registros de la papelera, los últimos borrados primero.
+func (ops *GormOperations[T]) FindDeleted() ([]T, error) {
+	var models []T
+	err := ops.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&models).Error
+	return models, err
+}
+
+// Restore saca el registro de la papelera si pasa el control registrado con
+// WithRestoreValidation.
+func (ops *GormOperations[T]) Restore(id string) (T, error) {
+	var model T
+	err := ops.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&model, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotInTrash
+			}
+			return err
+		}
+
+		if ops.validateRestore != nil {
+			if err := ops.validateRestore(tx, model); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(new(T)).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.First(&model, id).Error
+	})
+	return model, err
+}
+
+// PurgeDeleted borra definitivamente los registros que están en la papelera
+// desde antes de before. Cada uno va en su propia transacción: los que todavía
+// tienen referencias, o claves foráneas que lo impiden, se dejan para más
+// adelante sin frenar al resto.
+func (ops *GormOperations[T]) PurgeDeleted(before time.Time) (PurgeResult, error) {
+	var result PurgeResult
+	var ids []uint
+	if err := ops.db.Unscoped().Model(new(T)).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error; err != nil {
+		return result, err
+	}
+
+	for _, id := range ids {
+		err := ops.db.Transaction(func(tx *gorm.DB) error {
+			blocking, err := ops.blockingReferences(tx, fmt.Sprint(id))
+			if err != nil {
+				return err
+			}
+			if len(blocking) > 0 {
+				return &ReferenceError{References: blocking}
+			}
+			var model T
+			return tx.Unscoped().Delete(&model, id).Error
+		})
+		if err != nil {
+			log.Printf("No se purgó el registro %d de %T: %v", id, *new(T), err)
+			result.Skipped++
+			continue
+		}
+		result.Purged++
+	}
+	return result, nil
+}
+
+// WithMergeValidation registra el control que se hace antes de fusionar.
+func (ops *GormOperations[T]) WithMergeValidation(validator MergeValidator[T]) *GormOperations[T] {
+	ops.validateMerge = validator
+	return ops
+}
+
+// Merge pasa al registro survivorID todas las referencias de los duplicados,
+// incluso las que impiden borrarlos, y después los borra, todo en una
+// transacción. En la misma transacción guarda audit con el detalle de lo que
+// se movió: si no se puede auditar, no se fusiona.
+func (ops *GormOperations[T]) Merge(survivorID string, duplicateIDs []uint, audit models.AuditLog) (MergeResult[T], error) {
+	var result MergeResult[T]
+	id, err := strconv.ParseUint(survivorID, 10, 64)
+	if err != nil || len(duplicateIDs) == 0 || slices.Contains(duplicateIDs, uint(id)) {
+		return result, ErrInvalidMerge
+	}
+	duplicateIDs = slices.Clone(duplicateIDs)
+	slices.Sort(duplicateIDs)
+	duplicateIDs = slices.Compact(duplicateIDs)
+
+	err = ops.db.Transaction(func(tx *gorm.DB) error {
+		var survivor T
+		if err := tx.First(&survivor, survivorID).Error; err != nil {
+			return ErrInvalidMerge
+		}
+		var duplicates []T
+		if err := tx.Find(&duplicates, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if len(duplicates) != len(duplicateIDs) {
+			return ErrInvalidMerge
+		}
+
+		if ops.validateMerge != nil {
+			if err := ops.validateMerge(tx, survivor, duplicates); err != nil {
+				return err
+			}
+		}
+
+		for _, reference := range ops.references {
+			moved, err := moveReference(tx, reference, duplicateIDs, survivorID)
+			if err != nil {
+				return fmt.Errorf("error al mover %s: %v", reference.Name, err)
+			}
+			if moved > 0 {
+				result.Moved = append(result.Moved, MovedReference{Name: reference.Name, Count: moved})
+			}
+		}
+
+		if err := tx.Delete(new(T), duplicateIDs).Error; err != nil {
+			return err
+		}
+
+		result.Detail = ops.mergeDetail(survivorID, duplicateIDs, result.Moved)
+		audit.Detail = result.Detail
+		if err := tx.Create(&audit).Error; err != nil {
+			return fmt.Errorf("error al registrar la auditoría: %v", err)
+		}
+		return tx.First(&result.Survivor, survivorID).Error
+	})
+	if err != nil {
+		return MergeResult[T]{}, err
+	}
+	return result, nil
+}
+
+// mergeDetail describe la fusión para la auditoría, ej:
+// "brands 7, 9 fusionados en 3; movidos: 12 productos, 1 promociones".
+func (ops *GormOperations[T]) mergeDetail(survivorID string, duplicateIDs []uint, moved []MovedReference) string {
+	table := "registros"
+	stmt := &gorm.Statement{DB: ops.db}
+	if err := stmt.Parse(new(T)); err == nil {
+		table = stmt.Schema.Table
+	}
+
+	ids := make([]string, 0, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	parts := make([]string, 0, len(moved))
+	for _, reference := range moved {
+		parts = append(parts, fmt.Sprintf("%d %s", reference.Count, reference.Name))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "nada")
+	}
+	return fmt.Sprintf("%s %s fusionados en %s; movidos: %s", table, strings.Join(ids, ", "), survivorID, strings.Join(parts, ", "))
+}
+
+// moveReference pasa las filas de cada duplicado al registro que queda, uno
+// por vez para que las colisiones del índice único se resuelvan también
+// entre duplicados. Incluye las filas borradas, para que se puedan restaurar.
+func moveReference(tx *gorm.DB, reference Reference, duplicateIDs []uint, survivorID string) (int64, error) {
+	var moved int64
+	for _, duplicateID := range duplicateIDs {
+		if len(reference.Unique) > 0 {
+			if err := dropCollisions(tx, reference, duplicateID, survivorID); err != nil {
+				return moved, err
+			}
+		}
+
+		query := tx.Unscoped().Model(reference.Model).Where(reference.Column+" = ?", duplicateID)
+		if reference.Filter != "" {
+			query = query.Where(reference.Filter)
+		}
+		update := query.Updates(reassignValues(reference, survivorID))
+		if update.Error != nil {
+			return moved, update.Error
+		}
+		moved += update.RowsAffected
+	}
+	return moved, nil
+}
+
+// reassignValues arma la actualización de la columna y, si el modelo tiene
+// versión, la incrementa para que las ediciones que tenían la anterior fallen.
+func reassignValues(reference Reference, value interface{}) map[string]interface{} {
+	values := map[string]interface{}{reference.Column: value}
+	if _, ok := reference.Model.(models.VersionedModel); ok {
+		values["version"] = models.NextVersion()
+	}
+	return values
+}
+
+func dropCollisions(tx *gorm.DB, reference Reference, duplicateID uint, survivorID string) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(reference.Model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+
+	conditions := []string{"other." + reference.Column + " = @survivor"}
+	for _, column := range reference.Unique {
+		if column != reference.Column {
+			conditions = append(conditions, fmt.Sprintf("other.%s = %s.%s", column, table, column))
+		}
+	}
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = @duplicate AND EXISTS (SELECT 1 FROM %s AS other WHERE %s)",
+		table, reference.Column, table, strings.Join(conditions, " AND "))
+	if reference.Filter != "" {
+		sql += " AND " + reference.Filter
+	}
+	return tx.Exec(sql, map[string]interface{}{"survivor": survivorID, "duplicate": duplicateID}).Error
 }
 
 func (ops *GormOperations[T]) Pluck(field string) ([]string, error) {
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	trashPurgeInterval        = 24 * time.Hour
+)
+
+type purger interface {
+	PurgeDeleted(before time.Time) (PurgeResult, error)
+}
+
+// Trash agrupa los modelos con papelera: cada uno expone sus registros
+// borrados y se purga cuando vence el plazo de retención.
+type Trash struct {
+	retention time.Duration
+	purgers   map[string]purger
+}
+
+func NewTrash(retention time.Duration) *Trash {
+	return &Trash{retention: retention, purgers: map[string]purger{}}
+}
+
+// NewTrashFromEnv toma los días de retención de TRASH_RETENTION_DAYS, por
+// defecto 30.
+func NewTrashFromEnv() (*Trash, error) {
+	days := defaultTrashRetentionDays
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("TRASH_RETENTION_DAYS inválido: %q", value)
+		}
+		days = parsed
+	}
+	return NewTrash(time.Duration(days) * 24 * time.Hour), nil
+}
+
+// TrashRoutes registra el modelo en la papelera y agrega sus rutas:
+// GET /<name> lista los borrados y POST /<name>/:id/restore los restaura.
+func TrashRoutes[T any](trash *Trash, group *gin.RouterGroup, name string, ops Operations[T]) {
+	trash.purgers[name] = ops
+	group.GET("/"+name, GetDeleted(ops))
+	group.POST("/"+name+"/:id/restore", Restore(ops))
+}
+
+// Purge borra definitivamente lo que lleva en la papelera más que la retención.
+func (t *Trash) Purge() (map[string]PurgeResult, error) {
+	before := time.Now().Add(-t.retention)
+	results := make(map[string]PurgeResult, len(t.purgers))
+	for name, p := range t.purgers {
+		result, err := p.PurgeDeleted(before)
+		if err != nil {
+			return results, fmt.Errorf("error al purgar %s: %v", name, err)
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+// PurgeHandler adelanta la purga que se hace todos los días.
+func (t *Trash) PurgeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results, err := t.Purge()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// Start purga la papelera al arrancar y después una vez por día.
+func (t *Trash) Start() {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			results, err := t.Purge()
+			if err != nil {
+				log.Println("Error al purgar la papelera:", err)
+			}
+			for name, result := range results {
+				if result.Purged > 0 || result.Skipped > 0 {
+					log.Printf("Papelera de %s: %d purgados, %d pendientes por referencias", name, result.Purged, result.Skipped)
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -6,3 +6,120 @@ const (
 	STOCK_MOVEMENT_TYPE_IN StockMovementType = iota
 	STOCK_MOVEMENT_TYPE_OUT
 )
+
+type AccountMovementType int
+
+const (
+	ACCOUNT_MOVEMENT_TYPE_CREDIT AccountMovementType = iota
+	ACCOUNT_MOVEMENT_TYPE_DEBIT
+)
+
+type ReturnSettlement string
+
+const (
+	RETURN_SETTLEMENT_REFUND ReturnSettlement = "refund" // se devuelve el dinero
+	RETURN_SETTLEMENT_CREDIT ReturnSettlement = "credit" // queda a favor en la cuenta
+)
+
+type PromotionType string
+
+const (
+	PROMOTION_TYPE_PERCENTAGE  PromotionType = "percentage"  // porcentaje sobre el subtotal
+	PROMOTION_TYPE_FIXED       PromotionType = "fixed"       // monto fijo por unidad, o por pedido si el alcance es "order"
+	PROMOTION_TYPE_BUY_X_PAY_Y PromotionType = "buy_x_pay_y" // ej: 3x2
+)
+
+type PromotionScope string
+
+const (
+	PROMOTION_SCOPE_PRODUCT  PromotionScope = "product"
+	PROMOTION_SCOPE_CATEGORY PromotionScope = "category"
+	PROMOTION_SCOPE_BRAND    PromotionScope = "brand"
+	PROMOTION_SCOPE_ORDER    PromotionScope = "order"
+)
+
+type ImportErrorCode string
+
+const (
+	IMPORT_ERROR_REQUIRED           ImportErrorCode = "required"
+	IMPORT_ERROR_INVALID_NUMBER     ImportErrorCode = "invalid_number"
+	IMPORT_ERROR_OUT_OF_RANGE       ImportErrorCode = "out_of_range"
+	IMPORT_ERROR_TOO_LONG           ImportErrorCode = "too_long"
+	IMPORT_ERROR_DUPLICATE          ImportErrorCode = "duplicate"
+	IMPORT_ERROR_AMBIGUOUS_MATCH    ImportErrorCode = "ambiguous_match"
+	IMPORT_ERROR_CATEGORY_NOT_FOUND ImportErrorCode = "category_not_found"
+	IMPORT_ERROR_BRAND_NOT_FOUND    ImportErrorCode = "brand_not_found"
+	IMPORT_ERROR_SUPPLIER_NOT_FOUND ImportErrorCode = "supplier_not_found"
+)
+
+type ImportJobStatus string
+
+const (
+	IMPORT_JOB_STATUS_PENDING ImportJobStatus = "pending"
+	IMPORT_JOB_STATUS_RUNNING ImportJobStatus = "running"
+	IMPORT_JOB_STATUS_DONE    ImportJobStatus = "done"   // terminó, el reporte puede tener filas con errores
+	IMPORT_JOB_STATUS_FAILED  ImportJobStatus = "failed" // no se pudo procesar el archivo
+)
+
+// ImportJobPhase es la etapa de un trabajo en curso: primero se validan las
+// filas y, si no hay errores, se guardan.
+type ImportJobPhase string
+
+const (
+	IMPORT_JOB_PHASE_VALIDATING ImportJobPhase = "validating"
+	IMPORT_JOB_PHASE_SAVING     ImportJobPhase = "saving"
+)
+
+type ImportJobKind string
+
+const (
+	IMPORT_JOB_KIND_PRODUCTS ImportJobKind = "products"
+)
+
+type PriceListBatchStatus string
+
+const (
+	PRICE_LIST_BATCH_STATUS_PENDING   PriceListBatchStatus = "pending" // esperando revisión
+	PRICE_LIST_BATCH_STATUS_APPLIED   PriceListBatchStatus = "applied"
+	PRICE_LIST_BATCH_STATUS_DISCARDED PriceListBatchStatus = "discarded"
+)
+
+type PriceListItemStatus string
+
+const (
+	PRICE_LIST_ITEM_STATUS_CHANGED   PriceListItemStatus = "changed"
+	PRICE_LIST_ITEM_STATUS_NEW       PriceListItemStatus = "new" // no había costo previo de este proveedor
+	PRICE_LIST_ITEM_STATUS_UNCHANGED PriceListItemStatus = "unchanged"
+	PRICE_LIST_ITEM_STATUS_UNMATCHED PriceListItemStatus = "unmatched"
+	PRICE_LIST_ITEM_STATUS_INVALID   PriceListItemStatus = "invalid"
+)
+
+type PriceListMatch string
+
+const (
+	PRICE_LIST_MATCH_SUPPLIER_CODE PriceListMatch = "supplier_code"
+	PRICE_LIST_MATCH_BARCODE       PriceListMatch = "barcode"
+	PRICE_LIST_MATCH_SKU           PriceListMatch = "sku"
+)
+
+type BundlePricing string
+
+const (
+	BUNDLE_PRICING_FIXED      BundlePricing = "fixed"      // precio fijo del combo
+	BUNDLE_PRICING_COMPONENTS BundlePricing = "components" // suma de los componentes menos un descuento
+)
+
+type SchoolListLineStatus string
+
+const (
+	SCHOOL_LIST_LINE_MATCHED      SchoolListLineStatus = "matched"
+	SCHOOL_LIST_LINE_UNMAPPED     SchoolListLineStatus = "unmapped"     // la línea no tiene producto ni categoría asignados
+	SCHOOL_LIST_LINE_OUT_OF_STOCK SchoolListLineStatus = "out_of_stock" // no hay stock suficiente del producto o de la categoría
+)
+
+type SchoolListTarget string
+
+const (
+	SCHOOL_LIST_TARGET_BUDGET SchoolListTarget = "budget"
+	SCHOOL_LIST_TARGET_SELL   SchoolListTarget = "sell"
+)
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"fmt"
+	"libreria/requests"
 	"libreria/services"
 	"net/http"
 
@@ -15,18 +17,39 @@ func NewBudgetController(service services.BudgetService) *BudgetController {
 	return &BudgetController{service: service}
 }
 
-func (c *BudgetController) GetBudget() gin.HandlerFunc {
+// GetPDF genera el PDF del presupuesto; con ?images=true agrega la miniatura
+// de cada producto.
+func (c *BudgetController) GetPDF() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		id := ctx.Param("id")
+		withImages := ctx.Query("images") == "true"
 
-		// Generar el PDF
-		pdfBytes, err := c.service.GeneratePDF()
+		pdfBytes, err := c.service.GeneratePDF(id, withImages)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		ctx.Header("Content-Type", "application/pdf")
-		ctx.Header("Content-Disposition", "attachment; filename=presupuesto.pdf")
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=presupuesto_%s.pdf", id))
 		ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
 	}
 }
+
+func (c *BudgetController) CreateBudget() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.BudgetRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		budget, err := c.service.CreateBudget(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, budget)
+	}
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+	service services.CategoryService
+}
+
+func NewCategoryController(service services.CategoryService) *CategoryController {
+	return &CategoryController{service: service}
+}
+
+func (c *CategoryController) GetTree() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tree, err := c.service.Tree()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, tree)
+	}
+}
+
+func (c *CategoryController) Move() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.MoveCategoryRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		category, err := c.service.Move(uint(id), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, category)
+	}
+}
+
+func (c *CategoryController) Reprice() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.CategoryRepriceRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		updated, err := c.service.Reprice(uint(id), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"message": "Precios actualizados", "updated": updated})
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"libreria/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportJobController struct {
+	service services.ImportJobService
+}
+
+func NewImportJobController(service services.ImportJobService) *ImportJobController {
+	return &ImportJobController{service: service}
+}
+
+func (c *ImportJobController) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		job, err := c.service.GetByID(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Importación no encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusOK, job)
+	}
+}
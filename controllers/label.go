@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelController struct {
+	service services.LabelService
+}
+
+func NewLabelController(service services.LabelService) *LabelController {
+	return &LabelController{service: service}
+}
+
+func (c *LabelController) GetLabels() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.LabelRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		pdfBytes, err := c.service.GeneratePDF(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("Content-Disposition", "attachment; filename=etiquetas.pdf")
+		ctx.Data(http.StatusOK, "application/pdf", pdfBytes)
+	}
+}
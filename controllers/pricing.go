@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingController struct {
+	service services.PricingService
+}
+
+func NewPricingController(service services.PricingService) *PricingController {
+	return &PricingController{service: service}
+}
+
+// Evaluate valoriza un pedido con las promociones vigentes sin registrar nada.
+func (c *PricingController) Evaluate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.PricingRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		pricing, err := c.service.PriceLines(request.Items)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, pricing)
+	}
+}
@@ -1,18 +1,23 @@
 package controllers
 
 import (
+	"io"
+	"libreria/requests"
 	"libreria/services"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ProductController struct {
-	service services.ProductService
+	service    services.ProductService
+	jobService services.ImportJobService
 }
 
-func NewProductController(service services.ProductService) *ProductController {
-	return &ProductController{service: service}
+func NewProductController(service services.ProductService, jobService services.ImportJobService) *ProductController {
+	return &ProductController{service: service, jobService: jobService}
 }
 
 func (c *ProductController) FindAllWithCategoriesAndBrands() gin.HandlerFunc {
@@ -30,31 +35,58 @@ func (c *ProductController) FindAllWithCategoriesAndBrands() gin.HandlerFunc {
 
 func (c *ProductController) GetExport() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-
-		file, err := c.service.ExportToExcel()
-		if err != nil {
-			ctx.JSON(404, gin.H{"error": err.Error()})
+		var options requests.ProductExportOptions
+		if err := ctx.ShouldBindQuery(&options); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		filePath := "assets/templates/products.xlsx"
-		if err := file.SaveAs(filePath); err != nil {
-			ctx.JSON(500, gin.H{"error": "error al guardar el archivo Excel"})
-			return
+		// El archivo se escribe directo en la respuesta
+		var err error
+		if options.IsCSV() {
+			ctx.Header("Content-Disposition", "attachment; filename=products.csv")
+			ctx.Header("Content-Type", "text/csv; charset="+csvCharset(options.CSVOptions))
+			err = c.service.ExportToCSV(ctx.Writer, options)
+		} else {
+			ctx.Header("Content-Disposition", "attachment; filename=products.xlsx")
+			ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			err = c.service.ExportToExcel(ctx.Writer, options)
 		}
+		if err != nil {
+			exportFailed(ctx, err)
+		}
+	}
+}
 
-		ctx.FileAttachment(filePath, "products.xlsx")
+// exportFailed responde el error si todavía no se envió nada del archivo. Si
+// ya se enviaron los encabezados solo queda registrarlo y cortar la respuesta.
+func exportFailed(ctx *gin.Context, err error) {
+	if !ctx.Writer.Written() {
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	log.Printf("Error al exportar productos: %v", err)
+	ctx.Abort()
 }
 
-func (c *ProductController) ImportFromExcel() gin.HandlerFunc {
+func (c *ProductController) Import() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		var options requests.ProductImportOptions
+		if err := ctx.ShouldBindQuery(&options); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		fileHeader, err := ctx.FormFile("file")
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
 			return
 		}
 
+		options.DetectFormat(fileHeader.Filename)
+
 		file, err := fileHeader.Open()
 		if err != nil {
 			ctx.JSON(500, gin.H{"error": "No se pudo abrir el archivo"})
@@ -62,12 +94,211 @@ func (c *ProductController) ImportFromExcel() gin.HandlerFunc {
 		}
 		defer file.Close()
 
-		err = c.service.ImportFromExcel(file)
+		// Devuelve el archivo original con las celdas con errores marcadas
+		if ctx.Query("annotate") == "true" {
+			annotated, err := c.service.AnnotateImportErrors(file, options)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			defer annotated.Close()
+
+			ctx.Header("Content-Disposition", "attachment; filename=errores-"+fileHeader.Filename)
+			ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			if err := annotated.Write(ctx.Writer); err != nil {
+				ctx.JSON(500, gin.H{"error": "error al generar el archivo Excel"})
+			}
+			return
+		}
+
+		// La simulación no guarda nada: se valida en el momento y se responde
+		// con el reporte por fila
+		if options.DryRun {
+			report, err := c.service.ImportProducts(file, options, nil)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, report)
+			return
+		}
+
+		// La importación se procesa en segundo plano; el avance y el reporte
+		// se consultan en GET /jobs/:id
+		content, err := io.ReadAll(file)
+		if err != nil {
+			ctx.JSON(500, gin.H{"error": "No se pudo leer el archivo"})
+			return
+		}
+
+		job, err := c.jobService.EnqueueProductImport(fileHeader.Filename, content, options)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+func (c *ProductController) FindByBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		product, err := c.service.FindByBarcode(ctx.Param("code"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, product)
+	}
+}
+
+func (c *ProductController) GetBarcodes() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		barcodes, err := c.service.GetBarcodes(uint(productID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, barcodes)
+	}
+}
+
+func (c *ProductController) AddBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.ProductBarcodeRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		barcode, err := c.service.AddBarcode(uint(productID), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, barcode)
+	}
+}
+
+func (c *ProductController) RemoveBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		if err := c.service.RemoveBarcode(uint(productID), ctx.Param("barcodeId")); err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Código de barras no encontrado"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "Eliminado con éxito"})
+	}
+}
+
+func (c *ProductController) Search() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var options requests.ProductSearchOptions
+		if err := ctx.ShouldBindQuery(&options); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := c.service.Search(options)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"message": "Importación exitosa"})
+		ctx.JSON(http.StatusOK, results)
+	}
+}
+
+func (c *ProductController) GetVariants() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parentID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		variants, err := c.service.GetVariants(uint(parentID))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, variants)
+	}
+}
+
+func (c *ProductController) CreateVariant() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parentID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.ProductVariantRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		variant, err := c.service.CreateVariant(uint(parentID), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, variant)
+	}
+}
+
+func (c *ProductController) UpdateVariant() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parentID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.ProductVariantRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		variant, err := c.service.UpdateVariant(uint(parentID), ctx.Param("variantId"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, variant)
+	}
+}
+
+func csvCharset(options requests.CSVOptions) string {
+	if options.IsWindows1252() {
+		return "windows-1252"
 	}
+	return "utf-8"
 }
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductBundleController struct {
+	service services.ProductBundleService
+}
+
+func NewProductBundleController(service services.ProductBundleService) *ProductBundleController {
+	return &ProductBundleController{service: service}
+}
+
+func (c *ProductBundleController) GetByProduct() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		bundle, err := c.service.GetByProduct(uint(productID))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, bundle)
+	}
+}
+
+func (c *ProductBundleController) Save() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.ProductBundleRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		bundle, err := c.service.Save(uint(productID), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, bundle)
+	}
+}
+
+func (c *ProductBundleController) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		if err := c.service.Delete(uint(productID)); err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"message": "Eliminado con éxito"})
+	}
+}
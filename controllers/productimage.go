@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"io"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductImageController struct {
+	service services.ProductImageService
+}
+
+func NewProductImageController(service services.ProductImageService) *ProductImageController {
+	return &ProductImageController{service: service}
+}
+
+func (c *ProductImageController) GetByProduct() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		images, err := c.service.GetByProduct(uint(productID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, images)
+	}
+}
+
+func (c *ProductImageController) Upload() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo abrir el archivo"})
+			return
+		}
+		defer file.Close()
+
+		image, err := c.service.Upload(uint(productID), file)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, image)
+	}
+}
+
+func (c *ProductImageController) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		if err := c.service.Delete(uint(productID), ctx.Param("imageId")); err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "Eliminado con éxito"})
+	}
+}
+
+// Serve devuelve el archivo de la imagen. Las claves no cambian una vez
+// subidas, así que se puede cachear sin vencimiento.
+func (c *ProductImageController) Serve(thumbnail bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		content, contentType, err := c.service.Open(ctx.Param("id"), thumbnail)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		defer content.Close()
+
+		ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+		ctx.Header("Content-Type", contentType)
+		ctx.Status(http.StatusOK)
+		io.Copy(ctx.Writer, content)
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductSupplierController struct {
+	service services.ProductSupplierService
+}
+
+func NewProductSupplierController(service services.ProductSupplierService) *ProductSupplierController {
+	return &ProductSupplierController{service: service}
+}
+
+func (c *ProductSupplierController) GetByProduct() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		productSuppliers, err := c.service.GetByProduct(uint(productID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, productSuppliers)
+	}
+}
+
+func (c *ProductSupplierController) GetCatalog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		productSuppliers, err := c.service.GetCatalog(uint(supplierID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, productSuppliers)
+	}
+}
+
+func (c *ProductSupplierController) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.ProductSupplierRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		productSupplier, err := c.service.Create(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, productSupplier)
+	}
+}
+
+func (c *ProductSupplierController) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.ProductSupplierRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		productSupplier, err := c.service.Update(ctx.Param("id"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, productSupplier)
+	}
+}
+
+func (c *ProductSupplierController) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := c.service.Delete(ctx.Param("id")); err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"message": "Eliminado con éxito"})
+	}
+}
+
+func (c *ProductSupplierController) ReorderSuggestions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var options requests.ReorderSuggestionOptions
+		if err := ctx.ShouldBindQuery(&options); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		suggestions, err := c.service.ReorderSuggestions(options)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, suggestions)
+	}
+}
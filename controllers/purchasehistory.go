@@ -1,11 +1,9 @@
 package controllers
 
 import (
-	"fmt"
 	"libreria/requests"
 	"libreria/services"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -35,19 +33,3 @@ func (c *PurchaseHistoryController) CreatePurchaseHistory() gin.HandlerFunc {
 		ctx.JSON(http.StatusCreated, purchase)
 	}
 }
-
-func (c *PurchaseHistoryController) DeletePurchaseHistory() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		id := ctx.Param("id")
-		purchaseHistoryID, err := strconv.ParseUint(id, 10, 64)
-		if err != nil {
-			fmt.Println("Conversion error:", err)
-			return
-		}
-		if err := c.service.DeletePurchase(purchaseHistoryID); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(204, nil)
-	}
-}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseReturnController struct {
+	service services.PurchaseReturnService
+}
+
+func NewPurchaseReturnController(service services.PurchaseReturnService) *PurchaseReturnController {
+	return &PurchaseReturnController{service: service}
+}
+
+func (c *PurchaseReturnController) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseReturns, err := c.service.GetAll()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, purchaseReturns)
+	}
+}
+
+func (c *PurchaseReturnController) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		purchaseReturn, err := c.service.GetByID(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Devolución no encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusOK, purchaseReturn)
+	}
+}
+
+func (c *PurchaseReturnController) CreatePurchaseReturn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.PurchaseReturnRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		purchaseReturn, err := c.service.CreateReturn(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, purchaseReturn)
+	}
+}
+
+func (c *PurchaseReturnController) GetSupplierAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		account, err := c.service.GetSupplierAccount(uint(supplierID))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, account)
+	}
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchoolListController struct {
+	service services.SchoolListService
+}
+
+func NewSchoolListController(service services.SchoolListService) *SchoolListController {
+	return &SchoolListController{service: service}
+}
+
+func (c *SchoolListController) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		list, err := c.service.GetByID(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, list)
+	}
+}
+
+func (c *SchoolListController) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.SchoolListRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		list, err := c.service.Create(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, list)
+	}
+}
+
+func (c *SchoolListController) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.SchoolListRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		list, err := c.service.Update(ctx.Param("id"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, list)
+	}
+}
+
+func (c *SchoolListController) MapLine() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.SchoolListLineMappingRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		line, err := c.service.MapLine(ctx.Param("id"), ctx.Param("lineId"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, line)
+	}
+}
+
+func (c *SchoolListController) Generate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.SchoolListGenerateRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := c.service.Generate(ctx.Param("id"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, result)
+	}
+}
@@ -34,13 +34,22 @@ func (c *SellHistoryController) CreateSellHistory() gin.HandlerFunc {
 	}
 }
 
-func (c *SellHistoryController) DeleteSellHistory() gin.HandlerFunc {
+// CreateSellHistories registra una venta de varios productos, valorizada en
+// conjunto para aplicar las promociones sobre el total.
+func (c *SellHistoryController) CreateSellHistories() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		id := ctx.Param("id")
-		if err := c.service.DeleteSell(id); err != nil {
+		var request requests.SellHistoryRequestArray
+		if err := ctx.ShouldBindJSON(&request); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		ctx.JSON(204, nil)
+
+		sells, err := c.service.CreateSells(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, sells)
 	}
 }
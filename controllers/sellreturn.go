@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SellReturnController struct {
+	service services.SellReturnService
+}
+
+func NewSellReturnController(service services.SellReturnService) *SellReturnController {
+	return &SellReturnController{service: service}
+}
+
+func (c *SellReturnController) GetAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sellReturns, err := c.service.GetAll()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, sellReturns)
+	}
+}
+
+func (c *SellReturnController) GetByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sellReturn, err := c.service.GetByID(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Devolución no encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusOK, sellReturn)
+	}
+}
+
+func (c *SellReturnController) CreateSellReturn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.SellReturnRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		sellReturn, err := c.service.CreateReturn(request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, sellReturn)
+	}
+}
+
+func (c *SellReturnController) GetCustomerAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		customerID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		account, err := c.service.GetCustomerAccount(uint(customerID))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, account)
+	}
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"libreria/requests"
+	"libreria/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupplierPriceListController struct {
+	service services.SupplierPriceListService
+}
+
+func NewSupplierPriceListController(service services.SupplierPriceListService) *SupplierPriceListController {
+	return &SupplierPriceListController{service: service}
+}
+
+func (c *SupplierPriceListController) GetMapping() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		mapping, err := c.service.GetMapping(uint(supplierID))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, mapping)
+	}
+}
+
+func (c *SupplierPriceListController) SaveMapping() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		var request requests.SupplierPriceListMappingRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		mapping, err := c.service.SaveMapping(uint(supplierID), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, mapping)
+	}
+}
+
+func (c *SupplierPriceListController) Upload() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Archivo requerido"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(500, gin.H{"error": "No se pudo abrir el archivo"})
+			return
+		}
+		defer file.Close()
+
+		batch, err := c.service.Upload(uint(supplierID), fileHeader.Filename, file)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, batch)
+	}
+}
+
+func (c *SupplierPriceListController) GetBatches() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supplierID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+			return
+		}
+
+		batches, err := c.service.GetBatches(uint(supplierID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, batches)
+	}
+}
+
+func (c *SupplierPriceListController) GetBatch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		batch, err := c.service.GetBatch(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, batch)
+	}
+}
+
+func (c *SupplierPriceListController) Apply() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request requests.ApplySupplierPriceListRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		batch, err := c.service.Apply(ctx.Param("id"), request)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, batch)
+	}
+}
+
+func (c *SupplierPriceListController) Discard() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		batch, err := c.service.Discard(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusOK, batch)
+	}
+}
@@ -56,7 +56,63 @@ func AutoMigrate() {
 		&models.ProductStock{},
 		&models.StockMovement{},
 		&models.AuditLog{},
+		&models.SellReturn{},
+		&models.SellReturnItem{},
+		&models.CustomerAccountMovement{},
+		&models.PurchaseReturn{},
+		&models.PurchaseReturnItem{},
+		&models.SupplierAccountMovement{},
+		&models.Promotion{},
+		&models.SellHistoryDiscount{},
+		&models.Budget{},
+		&models.ProductBarcode{},
+		&models.ImportJob{},
+		&models.ProductSupplier{},
+		&models.SupplierPriceListMapping{},
+		&models.SupplierPriceListBatch{},
+		&models.SupplierPriceListItem{},
+		&models.ProductImage{},
+		&models.ProductBundle{},
+		&models.BundleComponent{},
+		&models.SchoolList{},
+		&models.SchoolListLine{},
 	)
+	setupSearch()
+
+	// El código de barras era único en toda la tabla; ahora solo entre los
+	// productos vigentes, y eso se controla al validar
+	if err := postgresqlDB.Exec("DROP INDEX IF EXISTS idx_product_barcodes_code").Error; err != nil {
+		log.Fatal("Error actualizando los índices de códigos de barras:", err)
+	}
+}
+
+// setupSearch habilita las extensiones y los índices de la búsqueda de
+// productos. unaccent no es inmutable, por eso se envuelve en una función que
+// sí lo es para poder indexar por ella. El documento de texto completo es una
+// columna generada, fuera del modelo, para que la consulta y el índice usen
+// exactamente la misma expresión: código y SKU sin reglas de idioma, y nombre
+// y descripción en español.
+func setupSearch() {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS unaccent",
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS
+			$$ SELECT public.unaccent('public.unaccent', $1) $$
+			LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+		"CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING gin (immutable_unaccent(lower(name)) gin_trgm_ops)",
+		"DROP INDEX IF EXISTS idx_products_search",
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_document tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', code || ' ' || sku), 'A') ||
+			setweight(to_tsvector('spanish', immutable_unaccent(name)), 'A') ||
+			setweight(to_tsvector('spanish', immutable_unaccent(description)), 'C')
+		) STORED`,
+		"CREATE INDEX IF NOT EXISTS idx_products_search_document ON products USING gin (search_document)",
+	}
+	for _, statement := range statements {
+		if err := postgresqlDB.Exec(statement).Error; err != nil {
+			log.Fatal("Error preparando la búsqueda de productos:", err)
+		}
+	}
 }
 
 func DisconnectDB() {
@@ -8,33 +8,46 @@ import (
 	"gorm.io/gorm"
 )
 
+// auditedKey marca las requests cuya auditoría ya guardó el handler.
+const auditedKey = "audited"
+
 func AuditMiddleware(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Antes de procesar la request
-		start := time.Now()
-		ip := c.ClientIP()
-		route := c.FullPath()
-		method := c.Request.Method
-
-		// Si después usás auth, podés recuperar el ID del usuario del contexto
-		var userID *uint = nil
-		if id, exists := c.Get("user_id"); exists {
-			uid := id.(uint)
-			userID = &uid
-		}
+		audit := NewAuditLog(c)
 
 		// Continuar con el procesamiento
 		c.Next()
 
-		audit := models.AuditLog{
-			UserID:    userID,
-			Route:     route,
-			Method:    method,
-			IP:        ip,
-			RequestAt: start,
+		if c.GetBool(auditedKey) {
+			return
 		}
 
 		// Guardar en BD
 		go db.Create(&audit) // lo hacemos en goroutine para no bloquear la request
 	}
 }
+
+// NewAuditLog arma el registro de auditoría de la request en curso, para los
+// handlers que lo guardan dentro de su propia transacción.
+func NewAuditLog(c *gin.Context) models.AuditLog {
+	// Si después usás auth, podés recuperar el ID del usuario del contexto
+	var userID *uint = nil
+	if id, exists := c.Get("user_id"); exists {
+		uid := id.(uint)
+		userID = &uid
+	}
+
+	return models.AuditLog{
+		UserID:    userID,
+		Route:     c.FullPath(),
+		Method:    c.Request.Method,
+		IP:        c.ClientIP(),
+		RequestAt: time.Now(),
+	}
+}
+
+// MarkAudited avisa al middleware que el handler ya guardó la auditoría.
+func MarkAudited(c *gin.Context) {
+	c.Set(auditedKey, true)
+}
@@ -18,7 +18,8 @@ func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 		if allowed[origin] {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, If-Match")
+			c.Header("Access-Control-Expose-Headers", "ETag")
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
 
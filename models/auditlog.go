@@ -9,4 +9,5 @@ type AuditLog struct {
 	Method    string    `gorm:"type:varchar(10);not null;index:idx_route_method"`
 	IP        string    `gorm:"type:varchar(100);not null"`
 	RequestAt time.Time `gorm:"not null;index:idx_request_at"`
+	Detail    string    `gorm:"type:text" json:"detail"` // lo que hizo la operación, ej: qué registros se fusionaron
 }
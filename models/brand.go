@@ -4,6 +4,7 @@ import "gorm.io/gorm"
 
 type Brand struct {
 	gorm.Model
+	Versioned
 	Name     string    `gorm:"type:varchar(65);not null" json:"name"`
 	Products []Product `gorm:"foreignKey:BrandID" json:"-"`
 }
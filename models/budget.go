@@ -5,14 +5,19 @@ import (
 )
 
 type BudgetItem struct {
+	ProductID   uint    `json:"product_id"`
 	ProductName string  `json:"product_name"`
 	Quantity    int     `json:"quantity"`
 	UnitPrice   float64 `json:"unit_price"`
+	Discount    float64 `json:"discount"`
+	Subtotal    float64 `json:"subtotal"`
 }
 
 type Budget struct {
 	gorm.Model
-	ClientName  string       `gorm:"type:varchar(100)"`
-	Description string       `gorm:"type:text"`
-	Items       []BudgetItem `gorm:"type:jsonb"`
+	ClientName  string       `gorm:"type:varchar(100)" json:"client_name"`
+	Description string       `gorm:"type:text" json:"description"`
+	Items       []BudgetItem `gorm:"type:jsonb;serializer:json" json:"items"`
+	Discount    float64      `gorm:"type:decimal(10,2);not null;default:0" json:"discount"`
+	Total       float64      `gorm:"type:decimal(10,2);not null;default:0" json:"total"`
 }
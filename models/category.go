@@ -4,6 +4,9 @@ import "gorm.io/gorm"
 
 type Category struct {
 	gorm.Model
-	Name     string    `gorm:"type:varchar(65);not null" json:"name"`
-	Products []Product `gorm:"foreignKey:CategoryID" json:"-"`
+	Versioned
+	Name     string     `gorm:"type:varchar(65);not null" json:"name"`
+	ParentID *uint      `gorm:"index" json:"parent_id"` // nil en las categorías de primer nivel
+	Children []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	Products []Product  `gorm:"foreignKey:CategoryID" json:"-"`
 }
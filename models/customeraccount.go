@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+type CustomerAccountMovement struct {
+	gorm.Model
+	CustomerID   uint     `gorm:"not null;index" json:"customer_id"`
+	Customer     Customer `gorm:"foreignKey:CustomerID" json:"-"`
+	Amount       float64  `gorm:"type:decimal(10,2);not null" json:"amount"`
+	MovementType int      `gorm:"not null" json:"movement_type"` // crédito o débito
+	ReferenceID  *uint    `json:"reference_id"`                  // opcional: ID de la devolución
+	Note         string   `gorm:"type:varchar(150)" json:"note"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportJob es una importación encolada. El archivo se guarda junto con el
+// trabajo para poder retomarlo si el servidor se reinicia antes de procesarlo.
+type ImportJob struct {
+	gorm.Model
+	Kind       string          `gorm:"type:varchar(20);not null" json:"kind"`
+	Status     string          `gorm:"type:varchar(20);not null;index" json:"status"`
+	Phase      string          `gorm:"type:varchar(20)" json:"phase,omitempty"` // etapa a la que corresponden Processed y TotalRows
+	FileName   string          `gorm:"type:varchar(255)" json:"file_name"`
+	Content    []byte          `gorm:"type:bytea" json:"-"`
+	Options    json.RawMessage `gorm:"type:jsonb" json:"options"`
+	TotalRows  int             `gorm:"not null;default:0" json:"total_rows"`
+	Processed  int             `gorm:"not null;default:0" json:"processed"`
+	Report     json.RawMessage `gorm:"type:jsonb" json:"report,omitempty"`
+	Error      string          `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  *time.Time      `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+}
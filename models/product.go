@@ -4,18 +4,48 @@ import "gorm.io/gorm"
 
 type Product struct {
 	gorm.Model
+	Versioned
 	Code              string            `gorm:"type:varchar(20);not null" json:"code"`
 	Sku               string            `gorm:"type:varchar(20);not null" json:"sku"`
 	Name              string            `gorm:"type:varchar(65);not null" json:"name"`
 	ProfitMargin      float64           `gorm:"type:decimal(5,2);not null" json:"profit_margin"`
 	Description       string            `gorm:"type:varchar(150);not null" json:"description"`
-	CategoryID        uint              `gorm:"not null" json:"category_id"`
+	CategoryID        uint              `gorm:"not null;index" json:"category_id"`
 	Category          Category          `gorm:"foreignKey:CategoryID" json:"category"`
-	BrandID           uint              `gorm:"not null" json:"brand_id"`
+	BrandID           uint              `gorm:"not null;index" json:"brand_id"`
 	Brand             Brand             `gorm:"foreignKey:BrandID" json:"brand"`
 	StockMovements    []StockMovement   `gorm:"foreignKey:ProductID" json:"-"`
 	ProductStocks     []ProductStock    `gorm:"foreignKey:ProductID" json:"-"`
 	PriceLists        []PriceList       `gorm:"foreignKey:ProductID" json:"-"`
 	PurchaseHistories []PurchaseHistory `gorm:"foreignKey:ProductID" json:"-"`
 	SellHistories     []SellHistory     `gorm:"foreignKey:ProductID" json:"-"`
+	Barcodes          []ProductBarcode  `gorm:"foreignKey:ProductID" json:"barcodes,omitempty"`
+	Images            []ProductImage    `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	// Variantes: comparten margen, categoría y marca con el producto padre y
+	// tienen su propio SKU, códigos de barras, stock y, opcionalmente, precio.
+	ParentID      *uint             `gorm:"index" json:"parent_id"`
+	Variants      []Product         `gorm:"foreignKey:ParentID" json:"variants,omitempty"`
+	Attributes    map[string]string `gorm:"type:jsonb;serializer:json" json:"attributes,omitempty"` // ej: {"color": "azul", "rayado": "cuadriculado"}
+	PriceOverride *float64          `gorm:"type:decimal(10,2)" json:"price_override"`
+	Bundle        *ProductBundle    `gorm:"foreignKey:ProductID" json:"bundle,omitempty"`
+}
+
+// IsVariant indica si el producto es una variante de otro.
+func (p Product) IsVariant() bool {
+	return p.ParentID != nil
+}
+
+// AfterUpdate propaga a las variantes los datos que heredan del producto padre.
+func (p *Product) AfterUpdate(tx *gorm.DB) error {
+	if p.ID == 0 || p.IsVariant() {
+		return nil
+	}
+	return tx.Model(&Product{}).
+		Where("parent_id = ?", p.ID).
+		Updates(map[string]interface{}{
+			"profit_margin": p.ProfitMargin,
+			"category_id":   p.CategoryID,
+			"brand_id":      p.BrandID,
+			"version":       NextVersion(),
+		}).Error
 }
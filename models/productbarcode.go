@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+type ProductBarcode struct {
+	gorm.Model
+	ProductID uint `gorm:"not null;index" json:"product_id"`
+	// EAN-13, EAN-8 o UPC-A. Es único entre los productos vigentes: el de un
+	// producto en la papelera se puede volver a usar
+	Code string `gorm:"type:varchar(14);not null;index:idx_product_barcodes_lookup" json:"code"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// ProductBundle convierte a un producto en un combo armado con otros
+// productos. El combo no tiene stock propio: al venderlo se descuentan sus
+// componentes.
+type ProductBundle struct {
+	gorm.Model
+	ProductID  uint              `gorm:"not null;index" json:"product_id"`
+	Pricing    string            `gorm:"type:varchar(20);not null" json:"pricing"` // "fixed" o "components"
+	FixedPrice float64           `gorm:"type:decimal(10,2);not null;default:0" json:"fixed_price"`
+	Discount   float64           `gorm:"type:decimal(5,2);not null;default:0" json:"discount"` // porcentaje sobre la suma de los componentes
+	Components []BundleComponent `gorm:"foreignKey:BundleID" json:"components"`
+}
+
+type BundleComponent struct {
+	gorm.Model
+	BundleID  uint    `gorm:"not null;index" json:"bundle_id"`
+	ProductID uint    `gorm:"not null;index" json:"product_id"`
+	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
+	Quantity  int     `gorm:"not null" json:"quantity"`
+}
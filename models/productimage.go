@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type ProductImage struct {
+	gorm.Model
+	ProductID    uint   `gorm:"not null;index" json:"product_id"`
+	Key          string `gorm:"type:varchar(255);not null" json:"-"` // clave en el almacenamiento
+	ThumbnailKey string `gorm:"type:varchar(255);not null" json:"-"`
+	ContentType  string `gorm:"type:varchar(50);not null" json:"content_type"`
+	Width        int    `gorm:"not null" json:"width"`
+	Height       int    `gorm:"not null" json:"height"`
+	Size         int64  `gorm:"not null" json:"size"`
+	Position     int    `gorm:"not null;default:0" json:"position"` // la primera es la principal
+	URL          string `gorm:"-" json:"url"`
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url"`
+}
+
+// Las URLs no dependen de dónde se guarde el archivo, así siguen siendo
+// válidas si se cambia de almacenamiento.
+func (i *ProductImage) setURLs() {
+	i.URL = fmt.Sprintf("/api/v1/images/%d", i.ID)
+	i.ThumbnailURL = fmt.Sprintf("/api/v1/images/%d/thumbnail", i.ID)
+}
+
+func (i *ProductImage) AfterFind(tx *gorm.DB) error {
+	i.setURLs()
+	return nil
+}
+
+func (i *ProductImage) AfterCreate(tx *gorm.DB) error {
+	i.setURLs()
+	return nil
+}
@@ -4,6 +4,7 @@ import "gorm.io/gorm"
 
 type ProductStock struct {
 	gorm.Model
+	Versioned
 	ProductID uint    `gorm:"not null;unique" json:"product_id"`
 	Product   Product `gorm:"foreignKey:ProductID" json:"product"`
 	Quantity  int     `gorm:"not null" json:"quantity"` // stock actual
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductSupplier vincula un producto con un proveedor que lo vende, con el
+// código que usa ese proveedor, el último costo que informó o al que se le
+// compró y las condiciones para reponerlo.
+type ProductSupplier struct {
+	gorm.Model
+	ProductID    uint       `gorm:"not null;uniqueIndex:idx_product_supplier" json:"product_id"`
+	Product      Product    `gorm:"foreignKey:ProductID" json:"product"`
+	SupplierID   uint       `gorm:"not null;uniqueIndex:idx_product_supplier;index:idx_supplier_code" json:"supplier_id"`
+	Supplier     Supplier   `gorm:"foreignKey:SupplierID" json:"supplier"`
+	SupplierCode string     `gorm:"type:varchar(30);index:idx_supplier_code" json:"supplier_code"`
+	LastCost     float64    `gorm:"type:decimal(10,2);not null;default:0" json:"last_cost"`
+	LastCostAt   *time.Time `json:"last_cost_at"`
+	PackSize     int        `gorm:"not null;default:1" json:"pack_size"`      // unidades por bulto
+	LeadTimeDays int        `gorm:"not null;default:0" json:"lead_time_days"` // días desde el pedido hasta la entrega
+	Preferred    bool       `gorm:"not null;default:false" json:"preferred"`  // a lo sumo uno por producto
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Promotion struct {
+	gorm.Model
+	Versioned
+	Name          string    `gorm:"type:varchar(65);not null" json:"name"`
+	Type          string    `gorm:"type:varchar(20);not null" json:"type"`  // "percentage", "fixed", "buy_x_pay_y"
+	Scope         string    `gorm:"type:varchar(20);not null" json:"scope"` // "product", "category", "brand", "order"
+	ScopeID       *uint     `json:"scope_id"`                               // nulo cuando el alcance es "order"
+	Value         float64   `gorm:"type:decimal(10,2);not null;default:0" json:"value"`
+	BuyQuantity   int       `gorm:"not null;default:0" json:"buy_quantity"`
+	PayQuantity   int       `gorm:"not null;default:0" json:"pay_quantity"`
+	MinOrderTotal float64   `gorm:"type:decimal(10,2);not null;default:0" json:"min_order_total"`
+	StartsAt      time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt        time.Time `gorm:"not null;index" json:"ends_at"`
+	IsActive      bool      `gorm:"default:true" json:"is_active"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+type PurchaseReturn struct {
+	gorm.Model
+	SupplierID uint                 `gorm:"not null" json:"supplier_id"`
+	Supplier   Supplier             `gorm:"foreignKey:SupplierID" json:"supplier"`
+	Reason     string               `gorm:"type:varchar(150);not null" json:"reason"` // ej: fallado, artículo equivocado
+	Total      float64              `gorm:"type:decimal(10,2);not null" json:"total"`
+	Items      []PurchaseReturnItem `gorm:"foreignKey:PurchaseReturnID" json:"items"`
+}
+
+type PurchaseReturnItem struct {
+	gorm.Model
+	PurchaseReturnID  uint            `gorm:"not null" json:"purchase_return_id"`
+	PurchaseHistoryID uint            `gorm:"not null;index" json:"purchase_history_id"`
+	PurchaseHistory   PurchaseHistory `gorm:"foreignKey:PurchaseHistoryID" json:"-"`
+	ProductID         uint            `gorm:"not null" json:"product_id"`
+	Quantity          int             `gorm:"not null" json:"quantity"`
+	UnitCost          float64         `gorm:"type:decimal(10,2);not null" json:"unit_cost"`
+	Subtotal          float64         `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// SchoolList es una lista de útiles publicada por una escuela para un grado.
+type SchoolList struct {
+	gorm.Model
+	School string           `gorm:"type:varchar(100);not null" json:"school"`
+	Grade  string           `gorm:"type:varchar(50);not null" json:"grade"`
+	Year   int              `gorm:"not null" json:"year"`
+	Lines  []SchoolListLine `gorm:"foreignKey:SchoolListID" json:"lines"`
+}
+
+// SchoolListLine es un renglón de la lista tal como lo escribió la escuela.
+// Se asocia a un producto puntual o a una categoría de la que sirve cualquier
+// producto, ej: "1 regla de 20 cm" a la categoría Reglas.
+type SchoolListLine struct {
+	gorm.Model
+	SchoolListID uint      `gorm:"not null;index" json:"school_list_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	Text         string    `gorm:"type:varchar(150);not null" json:"text"`
+	Quantity     int       `gorm:"not null" json:"quantity"`
+	ProductID    *uint     `json:"product_id"`
+	Product      *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	CategoryID   *uint     `json:"category_id"`
+	Category     *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
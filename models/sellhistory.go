@@ -4,11 +4,31 @@ import "gorm.io/gorm"
 
 type SellHistory struct {
 	gorm.Model
-	ProductID   uint     `gorm:"not null" json:"product_id"`
-	Product     Product  `gorm:"foreignKey:ProductID" json:"product"`
-	CustomerID  uint     `gorm:"not null" json:"customer_id"`
-	Customer    Customer `gorm:"foreignKey:CustomerID" json:"customer"`
-	Price       float64  `gorm:"type:decimal(10,2);not null" json:"price"`
-	Quantity    int      `gorm:"not null" json:"quantity"`
-	AverageCost float64  `gorm:"type:decimal(10,2);not null" json:"average_cost"`
+	ProductID   uint                  `gorm:"not null" json:"product_id"`
+	Product     Product               `gorm:"foreignKey:ProductID" json:"product"`
+	CustomerID  uint                  `gorm:"not null" json:"customer_id"`
+	Customer    Customer              `gorm:"foreignKey:CustomerID" json:"customer"`
+	ListPrice   float64               `gorm:"type:decimal(10,2);not null;default:0" json:"list_price"` // precio unitario antes de descuentos
+	Discount    float64               `gorm:"type:decimal(10,2);not null;default:0" json:"discount"`   // descuento total de la línea
+	Price       float64               `gorm:"type:decimal(10,2);not null" json:"price"`                // precio unitario neto
+	Quantity    int                   `gorm:"not null" json:"quantity"`
+	AverageCost float64               `gorm:"type:decimal(10,2);not null" json:"average_cost"`
+	Discounts   []SellHistoryDiscount `gorm:"foreignKey:SellHistoryID" json:"discounts"`
+	// Composición del combo al momento de la venta, para devolver los componentes
+	BundleItems []SellBundleItem `gorm:"type:jsonb;serializer:json" json:"bundle_items,omitempty"`
+}
+
+type SellBundleItem struct {
+	ProductID   uint    `json:"product_id"`
+	Quantity    int     `json:"quantity"` // unidades por combo
+	AverageCost float64 `json:"average_cost"`
+}
+
+type SellHistoryDiscount struct {
+	gorm.Model
+	SellHistoryID uint      `gorm:"not null;index" json:"sell_history_id"`
+	PromotionID   uint      `gorm:"not null" json:"promotion_id"`
+	Promotion     Promotion `gorm:"foreignKey:PromotionID" json:"-"`
+	Name          string    `gorm:"type:varchar(65);not null" json:"name"`
+	Amount        float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+type SellReturn struct {
+	gorm.Model
+	CustomerID uint             `gorm:"not null" json:"customer_id"`
+	Customer   Customer         `gorm:"foreignKey:CustomerID" json:"customer"`
+	Reason     string           `gorm:"type:varchar(150);not null" json:"reason"`
+	Settlement string           `gorm:"type:varchar(20);not null" json:"settlement"` // "refund" o "credit"
+	Total      float64          `gorm:"type:decimal(10,2);not null" json:"total"`
+	Items      []SellReturnItem `gorm:"foreignKey:SellReturnID" json:"items"`
+}
+
+type SellReturnItem struct {
+	gorm.Model
+	SellReturnID  uint        `gorm:"not null" json:"sell_return_id"`
+	SellHistoryID uint        `gorm:"not null;index" json:"sell_history_id"`
+	SellHistory   SellHistory `gorm:"foreignKey:SellHistoryID" json:"-"`
+	ProductID     uint        `gorm:"not null" json:"product_id"`
+	Quantity      int         `gorm:"not null" json:"quantity"`
+	UnitPrice     float64     `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+	Subtotal      float64     `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+}
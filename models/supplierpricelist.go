@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SupplierPriceListMapping indica en qué columnas trae cada dato la lista de
+// precios de un proveedor. Las columnas se identifican por el texto del encabezado.
+type SupplierPriceListMapping struct {
+	gorm.Model
+	SupplierID        uint   `gorm:"not null;uniqueIndex" json:"supplier_id"`
+	Sheet             string `gorm:"type:varchar(65)" json:"sheet"` // vacío: la primera hoja
+	HeaderRow         int    `gorm:"not null;default:1" json:"header_row"`
+	CodeColumn        string `gorm:"type:varchar(65)" json:"code_column"` // código del proveedor
+	BarcodeColumn     string `gorm:"type:varchar(65)" json:"barcode_column"`
+	SkuColumn         string `gorm:"type:varchar(65)" json:"sku_column"`
+	DescriptionColumn string `gorm:"type:varchar(65)" json:"description_column"`
+	CostColumn        string `gorm:"type:varchar(65);not null" json:"cost_column"`
+	Delimiter         string `gorm:"type:varchar(5)" json:"delimiter"` // solo CSV
+	Encoding          string `gorm:"type:varchar(20)" json:"encoding"` // solo CSV
+	DecimalComma      bool   `gorm:"default:false" json:"decimal_comma"`
+}
+
+// SupplierPriceListBatch es una lista de precios subida para revisar antes de
+// aplicar los cambios de costo.
+type SupplierPriceListBatch struct {
+	gorm.Model
+	SupplierID uint                    `gorm:"not null;index" json:"supplier_id"`
+	Supplier   Supplier                `gorm:"foreignKey:SupplierID" json:"supplier"`
+	FileName   string                  `gorm:"type:varchar(255)" json:"file_name"`
+	Status     string                  `gorm:"type:varchar(20);not null" json:"status"`
+	AppliedAt  *time.Time              `json:"applied_at"`
+	Items      []SupplierPriceListItem `gorm:"foreignKey:BatchID" json:"items,omitempty"`
+}
+
+type SupplierPriceListItem struct {
+	gorm.Model
+	BatchID      uint     `gorm:"not null;index" json:"batch_id"`
+	Row          int      `gorm:"not null" json:"row"`
+	SupplierCode string   `gorm:"type:varchar(30)" json:"supplier_code"`
+	Barcode      string   `gorm:"type:varchar(14)" json:"barcode"`
+	Sku          string   `gorm:"type:varchar(20)" json:"sku"`
+	Description  string   `gorm:"type:varchar(150)" json:"description"`
+	ProductID    *uint    `json:"product_id"`
+	Product      *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	MatchedBy    string   `gorm:"type:varchar(20)" json:"matched_by"`
+	PreviousCost *float64 `gorm:"type:decimal(10,2)" json:"previous_cost"`
+	NewCost      float64  `gorm:"type:decimal(10,2);not null;default:0" json:"new_cost"`
+	Status       string   `gorm:"type:varchar(20);not null" json:"status"`
+	Message      string   `gorm:"type:varchar(255)" json:"message,omitempty"`
+	Applied      bool     `gorm:"default:false" json:"applied"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrVersionConflict = errors.New("el registro cambió desde que se leyó; hay que volver a cargarlo antes de guardar")
+
+// Versioned agrega la versión para el control de concurrencia optimista: cada
+// actualización la incrementa y solo se aplica si nadie la cambió antes.
+type Versioned struct {
+	Version uint `gorm:"not null;default:1" json:"version"`
+}
+
+func (v Versioned) CurrentVersion() uint {
+	return v.Version
+}
+
+func (v *Versioned) SetVersion(version uint) {
+	v.Version = version
+}
+
+// VersionedModel es un puntero a un modelo que embebe Versioned.
+type VersionedModel interface {
+	CurrentVersion() uint
+	SetVersion(version uint)
+}
+
+// NextVersion incrementa la versión en las actualizaciones por columnas, ej:
+// Updates(map[string]interface{}{"parent_id": id, "version": models.NextVersion()}).
+func NextVersion() interface{} {
+	return gorm.Expr("version + 1")
+}
+
+// UpdateVersioned guarda todos los campos del modelo como Save, pero solo si
+// la versión en la base sigue siendo la que se leyó; si no, devuelve
+// ErrVersionConflict sin cambiar nada. No usa Save porque, cuando la
+// condición no encuentra la fila, Save la inserta pisando la existente.
+func UpdateVersioned(db *gorm.DB, model VersionedModel) error {
+	current := model.CurrentVersion()
+	model.SetVersion(current + 1)
+
+	result := db.Model(model).Where("version = ?", current).Select("*").Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		model.SetVersion(current)
+	}
+	return result.Error
+}
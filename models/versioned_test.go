@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// En modo DryRun ninguna fila coincide, como cuando otro ya cambió la versión
+func TestUpdateVersionedConflict(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("no se pudo abrir la base de prueba: %v", err)
+	}
+
+	var sql string
+	db.Callback().Update().After("gorm:update").Register("test:sql", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+	})
+
+	brand := Brand{Model: gorm.Model{ID: 1}, Name: "Bic", Versioned: Versioned{Version: 3}}
+	err = UpdateVersioned(db, &brand)
+
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("UpdateVersioned = %v, se esperaba ErrVersionConflict", err)
+	}
+	if brand.Version != 3 {
+		t.Errorf("versión = %d, tenía que volver a 3 después del conflicto", brand.Version)
+	}
+	if !strings.Contains(sql, `version = $`) || !strings.Contains(sql, `"version"=$`) {
+		t.Errorf("la actualización tiene que filtrar por la versión leída y guardar la siguiente: %s", sql)
+	}
+}
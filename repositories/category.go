@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	FindAll() ([]models.Category, error)
+	FindByID(id uint) (models.Category, error)
+	SubtreeIDs(id uint) ([]uint, error)
+	AncestorIDs(id uint) ([]uint, error)
+	Move(id uint, parentID *uint) error
+	FindForReprice(id uint) ([]models.Product, error)
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+// CategorySubtree devuelve una subconsulta con el ID de la categoría y los de
+// todas sus descendientes, para filtrar con "category_id IN (?)".
+func CategorySubtree(db *gorm.DB, id uint) *gorm.DB {
+	return db.Raw(`WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+			UNION
+			SELECT child.id FROM categories child
+			INNER JOIN subtree ON child.parent_id = subtree.id
+			WHERE child.deleted_at IS NULL
+		)
+		SELECT id FROM subtree`, id)
+}
+
+func (r *categoryRepository) FindAll() ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) FindByID(id uint) (models.Category, error) {
+	var category models.Category
+	err := r.db.First(&category, id).Error
+	return category, err
+}
+
+func (r *categoryRepository) SubtreeIDs(id uint) ([]uint, error) {
+	var ids []uint
+	err := CategorySubtree(r.db, id).Scan(&ids).Error
+	return ids, err
+}
+
+// AncestorIDs devuelve el ID de la categoría seguido de los de sus ancestros,
+// del más cercano a la raíz.
+func (r *categoryRepository) AncestorIDs(id uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = ? AND deleted_at IS NULL
+			UNION
+			SELECT parent.id, parent.parent_id, ancestors.depth + 1 FROM categories parent
+			INNER JOIN ancestors ON parent.id = ancestors.parent_id
+			WHERE parent.deleted_at IS NULL
+		)
+		SELECT id FROM ancestors ORDER BY depth`, id).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *categoryRepository) Move(id uint, parentID *uint) error {
+	return r.db.Model(&models.Category{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"parent_id": parentID, "version": models.NextVersion()}).Error
+}
+
+// FindForReprice devuelve los productos de la categoría y sus descendientes,
+// sin los combos: su precio sale de los componentes o es fijo.
+func (r *categoryRepository) FindForReprice(id uint) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Where("category_id IN (?)", CategorySubtree(r.db, id)).
+		Where("id NOT IN (?)", r.db.Model(&models.ProductBundle{}).Select("product_id")).
+		Find(&products).Error
+	return products, err
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"libreria/models"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDryRunDB arma las consultas sin ejecutarlas, para revisar el SQL sin un
+// Postgres.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("no se pudo abrir la base de prueba: %v", err)
+	}
+	return db
+}
+
+// lastQuery devuelve el SQL, con los valores ya interpolados, de la última
+// consulta de db.
+func lastQuery(db *gorm.DB) func() string {
+	var sql string
+	db.Callback().Query().After("gorm:query").Register("test:sql", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	return func() string { return sql }
+}
+
+func assertContains(t *testing.T, sql string, parts ...string) {
+	t.Helper()
+	normalized := strings.Join(strings.Fields(sql), " ")
+	for _, part := range parts {
+		if !strings.Contains(normalized, part) {
+			t.Errorf("falta %q en:\n%s", part, normalized)
+		}
+	}
+}
+
+func TestCategorySubtreeAsFilter(t *testing.T) {
+	db := newDryRunDB(t)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.Product{}).Where("category_id IN (?)", CategorySubtree(tx, 7)).Find(&[]models.Product{})
+	})
+
+	assertContains(t, sql,
+		// La categoría pedida es la base de la recursión
+		"category_id IN (WITH RECURSIVE subtree AS ( SELECT id FROM categories WHERE id = 7 AND deleted_at IS NULL",
+		// y cada paso suma los hijos de lo ya encontrado, sin las borradas
+		"UNION SELECT child.id FROM categories child INNER JOIN subtree ON child.parent_id = subtree.id WHERE child.deleted_at IS NULL )",
+		"SELECT id FROM subtree)",
+		`"products"."deleted_at" IS NULL`,
+	)
+}
+
+func TestCategoryFiltersUseSubtree(t *testing.T) {
+	db := newDryRunDB(t)
+	query := lastQuery(db)
+
+	// El recargo de precios alcanza a las subcategorías y deja afuera a los combos
+	if _, err := NewCategoryRepository(db).FindForReprice(3); err != nil {
+		t.Fatalf("FindForReprice: %v", err)
+	}
+	assertContains(t, query(),
+		"category_id IN (WITH RECURSIVE subtree AS ( SELECT id FROM categories WHERE id = 3",
+		`id NOT IN (SELECT "product_id" FROM "product_bundles"`,
+	)
+
+	categoryID := uint(4)
+	if _, err := NewProductRepository(db).FindForExport(&categoryID, nil); err != nil {
+		t.Fatalf("FindForExport: %v", err)
+	}
+	assertContains(t, query(), "products.category_id IN (WITH RECURSIVE subtree AS ( SELECT id FROM categories WHERE id = 4")
+
+	if _, err := NewProductRepository(db).FindForLabels(nil, &categoryID, nil); err != nil {
+		t.Fatalf("FindForLabels: %v", err)
+	}
+	assertContains(t, query(), "category_id IN (WITH RECURSIVE subtree AS ( SELECT id FROM categories WHERE id = 4")
+}
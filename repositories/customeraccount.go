@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"libreria/constants"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type CustomerAccountRepository interface {
+	Create(movement *models.CustomerAccountMovement) error
+	FindByCustomer(customerID uint) ([]models.CustomerAccountMovement, error)
+	Balance(customerID uint) (float64, error)
+}
+
+type customerAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerAccountRepository(db *gorm.DB) CustomerAccountRepository {
+	return &customerAccountRepository{db: db}
+}
+
+func (r *customerAccountRepository) Create(movement *models.CustomerAccountMovement) error {
+	return r.db.Create(movement).Error
+}
+
+func (r *customerAccountRepository) FindByCustomer(customerID uint) ([]models.CustomerAccountMovement, error) {
+	var movements []models.CustomerAccountMovement
+	err := r.db.Where("customer_id = ?", customerID).Order("created_at desc").Find(&movements).Error
+	return movements, err
+}
+
+// Balance devuelve el saldo a favor del cliente (créditos menos débitos).
+func (r *customerAccountRepository) Balance(customerID uint) (float64, error) {
+	var balance float64
+	err := r.db.Model(&models.CustomerAccountMovement{}).
+		Where("customer_id = ?", customerID).
+		Select("COALESCE(SUM(CASE WHEN movement_type = ? THEN amount ELSE -amount END), 0)", int(constants.ACCOUNT_MOVEMENT_TYPE_CREDIT)).
+		Scan(&balance).Error
+	return balance, err
+}
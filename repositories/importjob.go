@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"libreria/constants"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *models.ImportJob) error
+	FindByID(id string) (models.ImportJob, error)
+	NextPending() (models.ImportJob, error)
+	ResetRunning() error
+	UpdateProgress(id uint, phase constants.ImportJobPhase, processed, total int) error
+	Save(job *models.ImportJob) error
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+func (r *importJobRepository) Create(job *models.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *importJobRepository) FindByID(id string) (models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.Omit("content").First(&job, id).Error
+	return job, err
+}
+
+// NextPending devuelve el trabajo pendiente más antiguo, con su archivo.
+func (r *importJobRepository) NextPending() (models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.Where("status = ?", constants.IMPORT_JOB_STATUS_PENDING).Order("id").First(&job).Error
+	return job, err
+}
+
+// ResetRunning vuelve a encolar los trabajos que quedaron a medias por un
+// reinicio. Como la importación es todo o nada, se procesan desde el principio.
+func (r *importJobRepository) ResetRunning() error {
+	return r.db.Model(&models.ImportJob{}).
+		Where("status = ?", constants.IMPORT_JOB_STATUS_RUNNING).
+		Updates(map[string]interface{}{"status": constants.IMPORT_JOB_STATUS_PENDING, "phase": "", "processed": 0}).Error
+}
+
+func (r *importJobRepository) UpdateProgress(id uint, phase constants.ImportJobPhase, processed, total int) error {
+	return r.db.Model(&models.ImportJob{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"phase": phase, "processed": processed, "total_rows": total}).Error
+}
+
+func (r *importJobRepository) Save(job *models.ImportJob) error {
+	return r.db.Save(job).Error
+}
@@ -3,15 +3,28 @@ package repositories
 import (
 	"libreria/models"
 	"libreria/responses"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
 	FindAll() ([]responses.ProductResponse, error)
 	CreateMany(products []models.Product) (string, error)
-	ExistsByCodeAndName(code, name string) (bool, error)
-	ExistsBySku(sku string) (bool, error)
+	FindByCodesOrSkus(codes, skus []string) ([]models.Product, error)
+	UpdateCatalogFields(product *models.Product) error
+	FindByBarcode(codes []string) (responses.ProductLookupResponse, error)
+	FindBarcodes(productID uint) ([]models.ProductBarcode, error)
+	FindByBarcodes(codes []string) ([]models.ProductBarcode, error)
+	CreateBarcode(barcode *models.ProductBarcode) error
+	DeleteBarcode(productID uint, barcodeID string) error
+	FindForLabels(ids []uint, categoryID *uint, repricedSince *time.Time) ([]models.Product, error)
+	LastPriceChanges(ids []uint) (map[uint]time.Time, error)
+	FindForExport(categoryID, brandID *uint) ([]responses.ProductExportRow, error)
+	Search(term, tsquery string, barcodes []string, limit int) ([]responses.ProductSearchResult, error)
+	FindVariants(parentID uint) ([]models.Product, error)
+	Save(product *models.Product) error
 }
 
 type productRepository struct {
@@ -25,9 +38,10 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 func (r *productRepository) FindAll() ([]responses.ProductResponse, error) {
 	var products []responses.ProductResponse
 	err := r.db.Model(&models.Product{}).
-		Select("products.id, products.code, products.sku, products.name, products.profit_margin, products.description, category.name AS category_name, brand.name AS brand_name").
+		Select("products.id, products.code, products.sku, products.name, products.profit_margin, products.description, category.name AS category_name, brand.name AS brand_name, products.parent_id, products.attributes, products.price_override").
 		Joins("LEFT JOIN categories category ON category.id = products.category_id").
 		Joins("LEFT JOIN brands brand ON brand.id = products.brand_id").
+		Order("products.id").
 		Find(&products).Error
 	return products, err
 }
@@ -37,14 +51,264 @@ func (r *productRepository) CreateMany(products []models.Product) (string, error
 	return "Productos guardados con éxito", err
 }
 
-func (r *productRepository) ExistsByCodeAndName(code, name string) (bool, error) {
-	var count int64
-	err := r.db.Model(&models.Product{}).Where("code = ? AND name = ?", code, name).Count(&count).Error
-	return count > 0, err
+// Tamaño de los lotes de FindByCodesOrSkus, para no armar consultas con miles de parámetros
+const lookupBatchSize = 1000
+
+// FindByCodesOrSkus busca de una vez los productos que coinciden con alguno de
+// los códigos o SKUs recibidos.
+func (r *productRepository) FindByCodesOrSkus(codes, skus []string) ([]models.Product, error) {
+	var products []models.Product
+	seen := map[uint]bool{}
+	lookup := func(column string, values []string) error {
+		for start := 0; start < len(values); start += lookupBatchSize {
+			end := min(start+lookupBatchSize, len(values))
+			var batch []models.Product
+			if err := r.db.Where(column+" IN ?", values[start:end]).Find(&batch).Error; err != nil {
+				return err
+			}
+			for _, product := range batch {
+				if !seen[product.ID] {
+					seen[product.ID] = true
+					products = append(products, product)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := lookup("code", codes); err != nil {
+		return nil, err
+	}
+	if err := lookup("sku", skus); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpdateCatalogFields actualiza solo los datos que se mantienen desde la planilla.
+func (r *productRepository) UpdateCatalogFields(product *models.Product) error {
+	return r.db.Model(product).Updates(map[string]interface{}{
+		"name":          product.Name,
+		"profit_margin": product.ProfitMargin,
+		"description":   product.Description,
+		"category_id":   product.CategoryID,
+		"brand_id":      product.BrandID,
+		"version":       models.NextVersion(),
+	}).Error
+}
+
+// FindByBarcode resuelve en una sola consulta el producto, su categoría, su marca y su stock.
+func (r *productRepository) FindByBarcode(codes []string) (responses.ProductLookupResponse, error) {
+	var product responses.ProductLookupResponse
+	err := r.db.Model(&models.ProductBarcode{}).
+		Select("products.id, products.code, products.sku, products.name, products.profit_margin, products.description, category.name AS category_name, brand.name AS brand_name, products.parent_id, products.attributes, products.price_override, product_barcodes.code AS barcode, COALESCE(stock.quantity, 0) AS stock").
+		Joins("INNER JOIN products ON products.id = product_barcodes.product_id AND products.deleted_at IS NULL").
+		Joins("LEFT JOIN categories category ON category.id = products.category_id").
+		Joins("LEFT JOIN brands brand ON brand.id = products.brand_id").
+		Joins("LEFT JOIN product_stocks stock ON stock.product_id = products.id AND stock.deleted_at IS NULL").
+		Where("product_barcodes.code IN ?", codes).
+		Take(&product).Error
+	return product, err
+}
+
+func (r *productRepository) FindBarcodes(productID uint) ([]models.ProductBarcode, error) {
+	var barcodes []models.ProductBarcode
+	err := r.db.Where("product_id = ?", productID).Find(&barcodes).Error
+	return barcodes, err
+}
+
+func (r *productRepository) FindByBarcodes(codes []string) ([]models.ProductBarcode, error) {
+	var barcodes []models.ProductBarcode
+	for start := 0; start < len(codes); start += lookupBatchSize {
+		end := min(start+lookupBatchSize, len(codes))
+		var batch []models.ProductBarcode
+		err := r.db.Joins("INNER JOIN products ON products.id = product_barcodes.product_id AND products.deleted_at IS NULL").
+			Where("product_barcodes.code IN ?", codes[start:end]).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, batch...)
+	}
+	return barcodes, nil
+}
+
+func (r *productRepository) CreateBarcode(barcode *models.ProductBarcode) error {
+	return r.db.Create(barcode).Error
+}
+
+// DeleteBarcode borra físicamente el código para que pueda reasignarse a otro producto.
+func (r *productRepository) DeleteBarcode(productID uint, barcodeID string) error {
+	result := r.db.Unscoped().Where("product_id = ?", productID).Delete(&models.ProductBarcode{}, barcodeID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *productRepository) FindForLabels(ids []uint, categoryID *uint, repricedSince *time.Time) ([]models.Product, error) {
+	var products []models.Product
+	query := r.db.Preload("Barcodes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if categoryID != nil {
+		query = query.Where("category_id IN (?)", CategorySubtree(r.db, *categoryID))
+	}
+	if repricedSince != nil {
+		query = query.Where("id IN (?)", r.db.Model(&models.PriceList{}).
+			Select("product_id").
+			Where("is_active = ? AND effective_at >= ?", true, *repricedSince))
+	}
+	err := query.Order("name").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) LastPriceChanges(ids []uint) (map[uint]time.Time, error) {
+	var rows []struct {
+		ProductID   uint
+		EffectiveAt time.Time
+	}
+	err := r.db.Model(&models.PriceList{}).
+		Select("product_id, MAX(effective_at) AS effective_at").
+		Where("product_id IN ? AND is_active = ?", ids, true).
+		Group("product_id").
+		Scan(&rows).Error
+
+	changes := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		changes[row.ProductID] = row.EffectiveAt
+	}
+	return changes, err
+}
+
+// FindForExport devuelve el catálogo con el stock actual y el costo y
+// proveedor de la última compra de cada producto.
+func (r *productRepository) FindForExport(categoryID, brandID *uint) ([]responses.ProductExportRow, error) {
+	var products []responses.ProductExportRow
+	query := r.db.Model(&models.Product{}).
+		Select("products.id, products.code, products.sku, products.name, products.profit_margin, products.description, products.price_override, " +
+			"category.name AS category_name, brand.name AS brand_name, COALESCE(stock.quantity, 0) AS stock, " +
+			"COALESCE(last_purchase.cost, 0) AS last_cost, COALESCE(last_purchase.supplier_name, '') AS last_supplier").
+		Joins("LEFT JOIN categories category ON category.id = products.category_id").
+		Joins("LEFT JOIN brands brand ON brand.id = products.brand_id").
+		Joins("LEFT JOIN product_stocks stock ON stock.product_id = products.id AND stock.deleted_at IS NULL").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT ph.cost, supplier.name AS supplier_name
+			FROM purchase_histories ph
+			LEFT JOIN suppliers supplier ON supplier.id = ph.supplier_id
+			WHERE ph.product_id = products.id AND ph.deleted_at IS NULL
+			ORDER BY ph.created_at DESC
+			LIMIT 1
+		) last_purchase ON true`)
+	if categoryID != nil {
+		query = query.Where("products.category_id IN (?)", CategorySubtree(r.db, *categoryID))
+	}
+	if brandID != nil {
+		query = query.Where("products.brand_id = ?", *brandID)
+	}
+	err := query.Order("products.name").Find(&products).Error
+	return products, err
+}
+
+// searchNameSimilarity es el umbral de pg_trgm.word_similarity_threshold, que
+// usa el operador <% contra el índice de trigramas del nombre.
+const searchNameSimilarity = "0.4"
+
+// productSearchQuery usa @term (la búsqueda normalizada), @tsquery, @barcodes
+// y @limit.
+const productSearchQuery = `WITH query AS (
+		SELECT to_tsquery('spanish', @tsquery) AS value
+	), labels AS (
+		SELECT 'category' AS kind, id FROM categories
+		WHERE deleted_at IS NULL AND (
+			to_tsvector('spanish', immutable_unaccent(name)) @@ (SELECT value FROM query)
+			OR word_similarity(@term, immutable_unaccent(lower(name))) > 0.5)
+		UNION ALL
+		SELECT 'brand', id FROM brands
+		WHERE deleted_at IS NULL AND (
+			to_tsvector('spanish', immutable_unaccent(name)) @@ (SELECT value FROM query)
+			OR word_similarity(@term, immutable_unaccent(lower(name))) > 0.5)
+	), candidates AS (
+		SELECT id FROM products WHERE search_document @@ (SELECT value FROM query)
+		UNION
+		SELECT id FROM products WHERE @term <% immutable_unaccent(lower(name))
+		UNION
+		SELECT product_id FROM product_barcodes WHERE code IN @barcodes AND deleted_at IS NULL
+		UNION
+		SELECT id FROM products WHERE category_id IN (SELECT id FROM labels WHERE kind = 'category')
+		UNION
+		SELECT id FROM products WHERE brand_id IN (SELECT id FROM labels WHERE kind = 'brand')
+	)
+	SELECT products.id, products.code, products.sku, products.name, products.profit_margin, products.description,
+		category.name AS category_name, brand.name AS brand_name, products.parent_id, products.attributes, products.price_override,
+		COALESCE(stock.quantity, 0) AS stock,
+		ts_rank(products.search_document, query.value)
+			+ ts_rank(setweight(to_tsvector('spanish', immutable_unaccent(COALESCE(category.name, '') || ' ' || COALESCE(brand.name, ''))), 'B'), query.value)
+			+ GREATEST(
+				word_similarity(@term, immutable_unaccent(lower(products.name))),
+				word_similarity(@term, immutable_unaccent(lower(COALESCE(category.name, '')))),
+				word_similarity(@term, immutable_unaccent(lower(COALESCE(brand.name, ''))))
+			)
+			+ CASE WHEN lower(products.code) = @term OR lower(products.sku) = @term OR barcode.code IS NOT NULL THEN 1 ELSE 0 END AS rank
+	FROM candidates
+	JOIN products ON products.id = candidates.id AND products.deleted_at IS NULL
+	CROSS JOIN query
+	LEFT JOIN categories category ON category.id = products.category_id
+	LEFT JOIN brands brand ON brand.id = products.brand_id
+	LEFT JOIN product_stocks stock ON stock.product_id = products.id AND stock.deleted_at IS NULL
+	LEFT JOIN LATERAL (
+		SELECT code FROM product_barcodes
+		WHERE product_barcodes.product_id = products.id AND product_barcodes.code IN @barcodes AND product_barcodes.deleted_at IS NULL
+		LIMIT 1
+	) barcode ON true
+	ORDER BY rank DESC, products.name
+	LIMIT @limit`
+
+// Search combina la búsqueda de texto completo con la similitud por trigramas,
+// que tolera errores de tipeo en el nombre, la marca y la categoría. Las
+// coincidencias exactas de código, SKU o código de barras van primero.
+//
+// Los candidatos salen de una unión de condiciones que usan índices: la
+// columna search_document, el índice de trigramas del nombre, los códigos de
+// barras y las categorías y marcas parecidas, que son tablas chicas. El
+// puntaje se calcula solo sobre esos candidatos.
+func (r *productRepository) Search(term, tsquery string, barcodes []string, limit int) ([]responses.ProductSearchResult, error) {
+	var results []responses.ProductSearchResult
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", searchNameSimilarity).Error; err != nil {
+			return err
+		}
+
+		return tx.Raw(productSearchQuery,
+			map[string]interface{}{
+				"term":     term,
+				"tsquery":  tsquery,
+				"barcodes": barcodes,
+				"limit":    limit,
+			}).Scan(&results).Error
+	})
+	return results, err
+}
+
+func (r *productRepository) FindVariants(parentID uint) ([]models.Product, error) {
+	var variants []models.Product
+	err := r.db.Preload("Barcodes").
+		Where("parent_id = ?", parentID).
+		Order("name").
+		Find(&variants).Error
+	return variants, err
 }
 
-func (r *productRepository) ExistsBySku(sku string) (bool, error) {
-	var count int64
-	err := r.db.Model(&models.Product{}).Where("sku = ?", sku).Count(&count).Error
-	return count > 0, err
+// Save crea el producto o, si ya existe, lo actualiza controlando la versión.
+func (r *productRepository) Save(product *models.Product) error {
+	if product.ID == 0 {
+		return r.db.Omit(clause.Associations).Create(product).Error
+	}
+	return models.UpdateVersioned(r.db.Omit(clause.Associations), product)
 }
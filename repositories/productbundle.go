@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductBundleRepository interface {
+	FindByProduct(productID uint) (models.ProductBundle, error)
+	FindByProducts(productIDs []uint) ([]models.ProductBundle, error)
+	Save(bundle *models.ProductBundle) error
+	Delete(bundle *models.ProductBundle) error
+}
+
+type productBundleRepository struct {
+	db *gorm.DB
+}
+
+func NewProductBundleRepository(db *gorm.DB) ProductBundleRepository {
+	return &productBundleRepository{db: db}
+}
+
+func (r *productBundleRepository) FindByProduct(productID uint) (models.ProductBundle, error) {
+	var bundle models.ProductBundle
+	err := r.db.Preload("Components.Product").
+		Where("product_id = ?", productID).
+		First(&bundle).Error
+	return bundle, err
+}
+
+// FindByProducts devuelve los combos de los productos que lo son.
+func (r *productBundleRepository) FindByProducts(productIDs []uint) ([]models.ProductBundle, error) {
+	var bundles []models.ProductBundle
+	err := r.db.Preload("Components.Product").
+		Where("product_id IN ?", productIDs).
+		Find(&bundles).Error
+	return bundles, err
+}
+
+// Save guarda el combo y reemplaza sus componentes por los recibidos.
+func (r *productBundleRepository) Save(bundle *models.ProductBundle) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(bundle).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("bundle_id = ?", bundle.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		for i := range bundle.Components {
+			bundle.Components[i].BundleID = bundle.ID
+		}
+		return tx.Omit(clause.Associations).Create(&bundle.Components).Error
+	})
+}
+
+func (r *productBundleRepository) Delete(bundle *models.ProductBundle) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("bundle_id = ?", bundle.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(bundle).Error
+	})
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductImageRepository interface {
+	Create(image *models.ProductImage) error
+	FindByID(id string) (models.ProductImage, error)
+	FindByProduct(productID uint) ([]models.ProductImage, error)
+	FindPrimary(productIDs []uint) (map[uint]models.ProductImage, error)
+	NextPosition(productID uint) (int, error)
+	Delete(image *models.ProductImage) error
+}
+
+type productImageRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImageRepository(db *gorm.DB) ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+func (r *productImageRepository) Create(image *models.ProductImage) error {
+	return r.db.Create(image).Error
+}
+
+func (r *productImageRepository) FindByID(id string) (models.ProductImage, error) {
+	var image models.ProductImage
+	err := r.db.First(&image, id).Error
+	return image, err
+}
+
+func (r *productImageRepository) FindByProduct(productID uint) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := r.db.Where("product_id = ?", productID).Order("position, id").Find(&images).Error
+	return images, err
+}
+
+// FindPrimary devuelve la imagen principal de cada producto que tenga alguna.
+func (r *productImageRepository) FindPrimary(productIDs []uint) (map[uint]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := r.db.Raw(`SELECT DISTINCT ON (product_id) * FROM product_images
+		WHERE product_id IN ? AND deleted_at IS NULL
+		ORDER BY product_id, position, id`, productIDs).Scan(&images).Error
+
+	primary := make(map[uint]models.ProductImage, len(images))
+	for _, image := range images {
+		primary[image.ProductID] = image
+	}
+	return primary, err
+}
+
+func (r *productImageRepository) NextPosition(productID uint) (int, error) {
+	var position int
+	err := r.db.Model(&models.ProductImage{}).
+		Select("COALESCE(MAX(position) + 1, 0)").
+		Where("product_id = ?", productID).
+		Scan(&position).Error
+	return position, err
+}
+
+// Delete borra el registro definitivamente: el archivo se elimina del
+// almacenamiento junto con él.
+func (r *productImageRepository) Delete(image *models.ProductImage) error {
+	return r.db.Unscoped().Delete(image).Error
+}
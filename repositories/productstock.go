@@ -4,12 +4,14 @@ import (
 	"libreria/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductStockRepository interface {
 	Create(stock *models.ProductStock) error
-	FindByID(id uint) (models.ProductStock, error)
+	FindByProductID(productID uint) (models.ProductStock, error)
 	Update(productstock *models.ProductStock) error
+	Quantities(productIDs []uint) (map[uint]int, error)
 }
 
 type productStockRepository struct {
@@ -24,12 +26,26 @@ func (r *productStockRepository) Create(productstock *models.ProductStock) error
 	return r.db.Create(productstock).Error
 }
 
-func (r *productStockRepository) FindByID(id uint) (models.ProductStock, error) {
+func (r *productStockRepository) FindByProductID(productID uint) (models.ProductStock, error) {
 	var stock models.ProductStock
-	err := r.db.First(&stock, id).Error
+	err := r.db.Where("product_id = ?", productID).First(&stock).Error
 	return stock, err
 }
 
+// Update devuelve models.ErrVersionConflict si otro movimiento cambió el
+// stock desde que se leyó.
 func (r *productStockRepository) Update(productstock *models.ProductStock) error {
-	return r.db.Save(productstock).Error
+	return models.UpdateVersioned(r.db.Omit(clause.Associations), productstock)
+}
+
+// Quantities devuelve el stock de cada producto; los que no tienen registro no aparecen.
+func (r *productStockRepository) Quantities(productIDs []uint) (map[uint]int, error) {
+	var stocks []models.ProductStock
+	err := r.db.Where("product_id IN ?", productIDs).Find(&stocks).Error
+
+	quantities := make(map[uint]int, len(stocks))
+	for _, stock := range stocks {
+		quantities[stock.ProductID] = stock.Quantity
+	}
+	return quantities, err
 }
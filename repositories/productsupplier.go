@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"libreria/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductSupplierRepository interface {
+	FindByID(id string) (models.ProductSupplier, error)
+	FindByProduct(productID uint) ([]models.ProductSupplier, error)
+	FindBySupplier(supplierID uint) ([]models.ProductSupplier, error)
+	FindCatalog(supplierID uint) ([]models.ProductSupplier, error)
+	FindByProductAndSupplier(productID, supplierID uint) (models.ProductSupplier, error)
+	FindForReorder(supplierID *uint) ([]models.ProductSupplier, error)
+	Save(productSupplier *models.ProductSupplier) error
+	Delete(productSupplier *models.ProductSupplier) error
+	ClearPreferred(productID, exceptID uint) error
+	StockQuantities(productIDs []uint) (map[uint]int, error)
+	SoldQuantities(productIDs []uint, since time.Time) (map[uint]int, error)
+}
+
+type productSupplierRepository struct {
+	db *gorm.DB
+}
+
+func NewProductSupplierRepository(db *gorm.DB) ProductSupplierRepository {
+	return &productSupplierRepository{db: db}
+}
+
+func (r *productSupplierRepository) FindByID(id string) (models.ProductSupplier, error) {
+	var productSupplier models.ProductSupplier
+	err := r.db.Preload("Product").Preload("Supplier").First(&productSupplier, id).Error
+	return productSupplier, err
+}
+
+func (r *productSupplierRepository) FindByProduct(productID uint) ([]models.ProductSupplier, error) {
+	var productSuppliers []models.ProductSupplier
+	err := r.db.Preload("Supplier").Where("product_id = ?", productID).
+		Order("preferred desc, last_cost").Find(&productSuppliers).Error
+	return productSuppliers, err
+}
+
+func (r *productSupplierRepository) FindBySupplier(supplierID uint) ([]models.ProductSupplier, error) {
+	var productSuppliers []models.ProductSupplier
+	err := r.db.Where("supplier_id = ?", supplierID).Find(&productSuppliers).Error
+	return productSuppliers, err
+}
+
+// FindCatalog devuelve los productos que vende el proveedor.
+func (r *productSupplierRepository) FindCatalog(supplierID uint) ([]models.ProductSupplier, error) {
+	var productSuppliers []models.ProductSupplier
+	err := r.db.Preload("Product").
+		Joins("JOIN products ON products.id = product_suppliers.product_id AND products.deleted_at IS NULL").
+		Where("product_suppliers.supplier_id = ?", supplierID).
+		Order("products.name").Find(&productSuppliers).Error
+	return productSuppliers, err
+}
+
+func (r *productSupplierRepository) FindByProductAndSupplier(productID, supplierID uint) (models.ProductSupplier, error) {
+	var productSupplier models.ProductSupplier
+	err := r.db.Where("product_id = ? AND supplier_id = ?", productID, supplierID).First(&productSupplier).Error
+	return productSupplier, err
+}
+
+func (r *productSupplierRepository) FindForReorder(supplierID *uint) ([]models.ProductSupplier, error) {
+	var productSuppliers []models.ProductSupplier
+	query := r.db.Preload("Product").Preload("Supplier")
+	if supplierID != nil {
+		query = query.Where("supplier_id = ?", *supplierID)
+	}
+	err := query.Order("product_id").Find(&productSuppliers).Error
+	return productSuppliers, err
+}
+
+func (r *productSupplierRepository) Save(productSupplier *models.ProductSupplier) error {
+	return r.db.Omit("Product", "Supplier").Save(productSupplier).Error
+}
+
+func (r *productSupplierRepository) Delete(productSupplier *models.ProductSupplier) error {
+	return r.db.Delete(productSupplier).Error
+}
+
+// ClearPreferred quita la marca de preferido a los demás proveedores del producto.
+func (r *productSupplierRepository) ClearPreferred(productID, exceptID uint) error {
+	return r.db.Model(&models.ProductSupplier{}).
+		Where("product_id = ? AND id <> ? AND preferred = ?", productID, exceptID, true).
+		Update("preferred", false).Error
+}
+
+func (r *productSupplierRepository) StockQuantities(productIDs []uint) (map[uint]int, error) {
+	var stocks []models.ProductStock
+	err := r.db.Where("product_id IN ?", productIDs).Find(&stocks).Error
+
+	quantities := make(map[uint]int, len(stocks))
+	for _, stock := range stocks {
+		quantities[stock.ProductID] = stock.Quantity
+	}
+	return quantities, err
+}
+
+// SoldQuantities suma lo vendido de cada producto desde since. Las ventas de
+// combos se guardan con el ID del combo, así que se abren sus componentes
+// para contar las unidades que consumieron.
+func (r *productSupplierRepository) SoldQuantities(productIDs []uint, since time.Time) (map[uint]int, error) {
+	var rows []struct {
+		ProductID uint
+		Quantity  int
+	}
+	err := r.db.Raw(`
+		SELECT product_id, SUM(quantity) AS quantity
+		FROM (
+			SELECT sell.product_id, sell.quantity
+			FROM sell_histories sell
+			WHERE sell.deleted_at IS NULL AND sell.created_at >= @since
+			UNION ALL
+			SELECT (item->>'product_id')::bigint, (item->>'quantity')::int * sell.quantity
+			FROM sell_histories sell
+			CROSS JOIN LATERAL jsonb_array_elements(sell.bundle_items) AS item
+			WHERE sell.deleted_at IS NULL AND sell.created_at >= @since
+				AND jsonb_typeof(sell.bundle_items) = 'array'
+		) sold
+		WHERE product_id IN @ids
+		GROUP BY product_id`,
+		map[string]interface{}{"ids": productIDs, "since": since}).
+		Scan(&rows).Error
+
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.ProductID] = row.Quantity
+	}
+	return quantities, err
+}
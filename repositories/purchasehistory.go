@@ -9,7 +9,6 @@ import (
 type PurchaseHistoryRepository interface {
 	Create(purchase *models.PurchaseHistory) error
 	FindByID(purchaseHistoryID uint64) (models.PurchaseHistory, error)
-	Delete(purchaseHistoryID uint64) error
 }
 
 type purchaseHistoryRepository struct {
@@ -29,7 +28,3 @@ func (r *purchaseHistoryRepository) FindByID(purchaseHistoryID uint64) (models.P
 	err := r.db.First(&purchase, purchaseHistoryID).Error
 	return purchase, err
 }
-
-func (r *purchaseHistoryRepository) Delete(purchaseHistoryID uint64) error {
-	return r.db.Delete(&models.PurchaseHistory{}, purchaseHistoryID).Error
-}
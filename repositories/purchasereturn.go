@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseReturnRepository interface {
+	Create(purchaseReturn *models.PurchaseReturn) error
+	FindAll() ([]models.PurchaseReturn, error)
+	FindByID(id string) (models.PurchaseReturn, error)
+	ReturnedQuantity(purchaseHistoryID uint) (int, error)
+}
+
+type purchaseReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseReturnRepository(db *gorm.DB) PurchaseReturnRepository {
+	return &purchaseReturnRepository{db: db}
+}
+
+func (r *purchaseReturnRepository) Create(purchaseReturn *models.PurchaseReturn) error {
+	return r.db.Create(purchaseReturn).Error
+}
+
+func (r *purchaseReturnRepository) FindAll() ([]models.PurchaseReturn, error) {
+	var purchaseReturns []models.PurchaseReturn
+	err := r.db.Preload("Items").Preload("Supplier").Order("created_at desc").Find(&purchaseReturns).Error
+	return purchaseReturns, err
+}
+
+func (r *purchaseReturnRepository) FindByID(id string) (models.PurchaseReturn, error) {
+	var purchaseReturn models.PurchaseReturn
+	err := r.db.Preload("Items").Preload("Supplier").First(&purchaseReturn, id).Error
+	return purchaseReturn, err
+}
+
+func (r *purchaseReturnRepository) ReturnedQuantity(purchaseHistoryID uint) (int, error) {
+	var total int
+	err := r.db.Model(&models.PurchaseReturnItem{}).
+		Where("purchase_history_id = ?", purchaseHistoryID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchoolListRepository interface {
+	FindByID(id string) (models.SchoolList, error)
+	FindLine(listID, lineID string) (models.SchoolListLine, error)
+	Save(list *models.SchoolList) error
+	SaveLine(line *models.SchoolListLine) error
+	FindInStockByCategory(categoryID uint, quantity int) ([]models.Product, error)
+}
+
+type schoolListRepository struct {
+	db *gorm.DB
+}
+
+func NewSchoolListRepository(db *gorm.DB) SchoolListRepository {
+	return &schoolListRepository{db: db}
+}
+
+func (r *schoolListRepository) FindByID(id string) (models.SchoolList, error) {
+	var list models.SchoolList
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).
+		Preload("Lines.Product").
+		Preload("Lines.Category").
+		First(&list, id).Error
+	return list, err
+}
+
+func (r *schoolListRepository) FindLine(listID, lineID string) (models.SchoolListLine, error) {
+	var line models.SchoolListLine
+	err := r.db.Where("school_list_id = ?", listID).First(&line, lineID).Error
+	return line, err
+}
+
+// Save guarda la lista y reemplaza sus líneas por las recibidas.
+func (r *schoolListRepository) Save(list *models.SchoolList) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(list).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("school_list_id = ?", list.ID).Delete(&models.SchoolListLine{}).Error; err != nil {
+			return err
+		}
+		for i := range list.Lines {
+			list.Lines[i].SchoolListID = list.ID
+		}
+		return tx.Omit(clause.Associations).Create(&list.Lines).Error
+	})
+}
+
+func (r *schoolListRepository) SaveLine(line *models.SchoolListLine) error {
+	return r.db.Omit(clause.Associations).Save(line).Error
+}
+
+// FindInStockByCategory devuelve los productos de la categoría, o de sus
+// subcategorías, con stock suficiente, los de más stock primero.
+func (r *schoolListRepository) FindInStockByCategory(categoryID uint, quantity int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Joins("INNER JOIN product_stocks stock ON stock.product_id = products.id AND stock.deleted_at IS NULL").
+		Where("products.category_id IN (?) AND stock.quantity >= ?", CategorySubtree(r.db, categoryID), quantity).
+		Order("stock.quantity DESC").
+		Limit(20).
+		Find(&products).Error
+	return products, err
+}
@@ -9,7 +9,6 @@ import (
 type SellHistoryRepository interface {
 	Create(sell *models.SellHistory) error
 	FindByID(id string) (models.SellHistory, error)
-	Delete(id string) error
 }
 
 type sellHistoryRepository struct {
@@ -29,7 +28,3 @@ func (r *sellHistoryRepository) FindByID(id string) (models.SellHistory, error)
 	err := r.db.First(&Sell, id).Error
 	return Sell, err
 }
-
-func (r *sellHistoryRepository) Delete(id string) error {
-	return r.db.Delete(&models.SellHistory{}, id).Error
-}
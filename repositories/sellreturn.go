@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type SellReturnRepository interface {
+	Create(sellReturn *models.SellReturn) error
+	FindAll() ([]models.SellReturn, error)
+	FindByID(id string) (models.SellReturn, error)
+	ReturnedQuantity(sellHistoryID uint) (int, error)
+}
+
+type sellReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewSellReturnRepository(db *gorm.DB) SellReturnRepository {
+	return &sellReturnRepository{db: db}
+}
+
+func (r *sellReturnRepository) Create(sellReturn *models.SellReturn) error {
+	return r.db.Create(sellReturn).Error
+}
+
+func (r *sellReturnRepository) FindAll() ([]models.SellReturn, error) {
+	var sellReturns []models.SellReturn
+	err := r.db.Preload("Items").Preload("Customer").Order("created_at desc").Find(&sellReturns).Error
+	return sellReturns, err
+}
+
+func (r *sellReturnRepository) FindByID(id string) (models.SellReturn, error) {
+	var sellReturn models.SellReturn
+	err := r.db.Preload("Items").Preload("Customer").First(&sellReturn, id).Error
+	return sellReturn, err
+}
+
+func (r *sellReturnRepository) ReturnedQuantity(sellHistoryID uint) (int, error) {
+	var total int
+	err := r.db.Model(&models.SellReturnItem{}).
+		Where("sell_history_id = ?", sellHistoryID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"libreria/constants"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type SupplierAccountRepository interface {
+	Create(movement *models.SupplierAccountMovement) error
+	FindBySupplier(supplierID uint) ([]models.SupplierAccountMovement, error)
+	Balance(supplierID uint) (float64, error)
+}
+
+type supplierAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierAccountRepository(db *gorm.DB) SupplierAccountRepository {
+	return &supplierAccountRepository{db: db}
+}
+
+func (r *supplierAccountRepository) Create(movement *models.SupplierAccountMovement) error {
+	return r.db.Create(movement).Error
+}
+
+func (r *supplierAccountRepository) FindBySupplier(supplierID uint) ([]models.SupplierAccountMovement, error) {
+	var movements []models.SupplierAccountMovement
+	err := r.db.Where("supplier_id = ?", supplierID).Order("created_at desc").Find(&movements).Error
+	return movements, err
+}
+
+// Balance devuelve el crédito a favor con el proveedor (créditos menos débitos).
+func (r *supplierAccountRepository) Balance(supplierID uint) (float64, error) {
+	var balance float64
+	err := r.db.Model(&models.SupplierAccountMovement{}).
+		Where("supplier_id = ?", supplierID).
+		Select("COALESCE(SUM(CASE WHEN movement_type = ? THEN amount ELSE -amount END), 0)", int(constants.ACCOUNT_MOVEMENT_TYPE_CREDIT)).
+		Scan(&balance).Error
+	return balance, err
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type SupplierPriceListRepository interface {
+	FindMapping(supplierID uint) (models.SupplierPriceListMapping, error)
+	SaveMapping(mapping *models.SupplierPriceListMapping) error
+	CreateBatch(batch *models.SupplierPriceListBatch) error
+	FindBatches(supplierID uint) ([]models.SupplierPriceListBatch, error)
+	FindBatch(id string) (models.SupplierPriceListBatch, error)
+	UpdateBatchStatus(batch *models.SupplierPriceListBatch) error
+	MarkItemsApplied(ids []uint) error
+}
+
+type supplierPriceListRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierPriceListRepository(db *gorm.DB) SupplierPriceListRepository {
+	return &supplierPriceListRepository{db: db}
+}
+
+func (r *supplierPriceListRepository) FindMapping(supplierID uint) (models.SupplierPriceListMapping, error) {
+	var mapping models.SupplierPriceListMapping
+	err := r.db.Where("supplier_id = ?", supplierID).First(&mapping).Error
+	return mapping, err
+}
+
+func (r *supplierPriceListRepository) SaveMapping(mapping *models.SupplierPriceListMapping) error {
+	return r.db.Save(mapping).Error
+}
+
+// CreateBatch guarda la cabecera y después los ítems en lotes, porque una
+// lista de precios puede tener miles de filas.
+func (r *supplierPriceListRepository) CreateBatch(batch *models.SupplierPriceListBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		items := batch.Items
+		if err := tx.Omit("Items").Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].BatchID = batch.ID
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(&items, 500).Error; err != nil {
+				return err
+			}
+		}
+		batch.Items = items
+		return nil
+	})
+}
+
+func (r *supplierPriceListRepository) FindBatches(supplierID uint) ([]models.SupplierPriceListBatch, error) {
+	var batches []models.SupplierPriceListBatch
+	err := r.db.Where("supplier_id = ?", supplierID).Order("created_at desc").Find(&batches).Error
+	return batches, err
+}
+
+func (r *supplierPriceListRepository) FindBatch(id string) (models.SupplierPriceListBatch, error) {
+	var batch models.SupplierPriceListBatch
+	err := r.db.Preload("Supplier").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("row")
+		}).
+		Preload("Items.Product").
+		First(&batch, id).Error
+	return batch, err
+}
+
+func (r *supplierPriceListRepository) UpdateBatchStatus(batch *models.SupplierPriceListBatch) error {
+	return r.db.Model(batch).Select("status", "applied_at").Updates(batch).Error
+}
+
+func (r *supplierPriceListRepository) MarkItemsApplied(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.SupplierPriceListItem{}).Where("id IN ?", ids).Update("applied", true).Error
+}
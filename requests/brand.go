@@ -73,3 +73,9 @@ func (r BrandRequestArray) Validate(db *gorm.DB) error {
 	}
 	return nil
 }
+
+// ValidateBrandRestore controla que nadie haya tomado el nombre de la marca
+// mientras estaba en la papelera.
+func ValidateBrandRestore(db *gorm.DB, brand models.Brand) error {
+	return BrandRequest{Name: brand.Name}.Validate(db)
+}
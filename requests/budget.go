@@ -5,9 +5,9 @@ import (
 )
 
 type BudgetRequest struct {
-	ClientName  string   `json:"client_name" binding:"required"`
-	Description string   `json:"description"`
-	Items       []string `json:"items" binding:"required"`
+	ClientName  string               `json:"client_name" binding:"required"`
+	Description string               `json:"description"`
+	Items       []PricingLineRequest `json:"items" binding:"required,min=1,dive"`
 }
 
 func (r BudgetRequest) ToModel() (models.Budget, error) {
@@ -2,6 +2,7 @@ package requests
 
 import (
 	"errors"
+	"fmt"
 	"libreria/models"
 
 	"gorm.io/gorm"
@@ -9,15 +10,21 @@ import (
 
 type CategoryRequestArray []CategoryRequest
 type CategoryRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=65"`
+	Name     string `json:"name" binding:"required,min=1,max=65"`
+	ParentID *uint  `json:"parent_id"`
 }
 
 func (r CategoryRequest) ToModel() (models.Category, error) {
-	return models.Category{Name: r.Name}, nil
+	return models.Category{Name: r.Name, ParentID: r.ParentID}, nil
 }
 
+// UpdateModel cambia de padre solo si se envía parent_id; para pasar una
+// categoría al primer nivel está el endpoint de mover.
 func (r CategoryRequest) UpdateModel(existing models.Category) (models.Category, error) {
 	existing.Name = r.Name
+	if r.ParentID != nil {
+		existing.ParentID = r.ParentID
+	}
 	return existing, nil
 }
 
@@ -34,31 +41,38 @@ func (r CategoryRequestArray) ToArrayModel() ([]models.Category, error) {
 }
 
 func (r CategoryRequest) Validate(db *gorm.DB) error {
-	var count int64
-	if err := db.Model(&models.Category{}).Where("name = ?", r.Name).Count(&count).Error; err != nil {
+	if err := ValidateCategoryName(db, r.Name, r.ParentID, 0); err != nil {
 		return err
 	}
-	if count > 0 {
-		return errors.New("el nombre de la categoría ya existe")
-	}
-	return nil
+	return MoveCategoryRequest{ParentID: r.ParentID}.Validate(db, 0)
 }
 
 func (r CategoryRequest) ValidateUpdate(db *gorm.DB, existing models.Category) error {
-	if r.Name == existing.Name {
-		return nil
+	if err := (MoveCategoryRequest{ParentID: r.ParentID}).Validate(db, existing.ID); err != nil {
+		return err
+	}
+
+	updated, err := r.UpdateModel(existing)
+	if err != nil {
+		return err
 	}
+	return ValidateCategoryName(db, updated.Name, updated.ParentID, existing.ID)
+}
 
+// ValidateCategoryName controla que ninguna otra categoría con el mismo padre
+// use el nombre. En ramas distintas el nombre se puede repetir, ej: "Cuadernos"
+// dentro de "Escolar" y dentro de "Oficina".
+func ValidateCategoryName(db *gorm.DB, name string, parentID *uint, categoryID uint) error {
 	var count int64
 	if err := db.Model(&models.Category{}).
-		Where("name = ?", r.Name).
-		Where("id != ?", existing.ID).
+		Where("name = ? AND parent_id IS NOT DISTINCT FROM ?", name, parentID).
+		Where("id != ?", categoryID).
 		Count(&count).Error; err != nil {
 		return err
 	}
 
 	if count > 0 {
-		return errors.New("ya existe otra categoría con ese nombre")
+		return fmt.Errorf("ya existe una categoría '%s' en ese nivel", name)
 	}
 	return nil
 }
@@ -73,3 +87,62 @@ func (r CategoryRequestArray) Validate(db *gorm.DB) error {
 	}
 	return nil
 }
+
+// MoveCategoryRequest cuelga la categoría, con todas sus subcategorías, de
+// otra categoría; sin ParentID pasa al primer nivel.
+type MoveCategoryRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+// Validate controla que el nuevo padre exista y que no sea la categoría
+// misma ni una de sus descendientes, lo que armaría un ciclo.
+func (r MoveCategoryRequest) Validate(db *gorm.DB, categoryID uint) error {
+	if r.ParentID == nil {
+		return nil
+	}
+
+	var parent models.Category
+	if err := db.First(&parent, *r.ParentID).Error; err != nil {
+		return fmt.Errorf("categoría padre con ID %d no encontrada", *r.ParentID)
+	}
+	if categoryID == 0 {
+		return nil
+	}
+
+	// Se sube desde el nuevo padre hasta la raíz buscando la categoría movida
+	for current := &parent; current != nil; {
+		if current.ID == categoryID {
+			return errors.New("una categoría no puede moverse dentro de sí misma ni de una de sus subcategorías")
+		}
+		if current.ParentID == nil {
+			break
+		}
+		var next models.Category
+		if err := db.First(&next, *current.ParentID).Error; err != nil {
+			break
+		}
+		current = &next
+	}
+	return nil
+}
+
+type CategoryRepriceRequest struct {
+	Percentage float64 `json:"percentage" binding:"required,gt=-100,lte=1000"` // ej: 10 sube un 10%, -5 baja un 5%
+}
+
+// ValidateCategoryRestore controla que el nombre siga libre y que la
+// categoría padre no esté también borrada.
+func ValidateCategoryRestore(db *gorm.DB, category models.Category) error {
+	return CategoryRequest{Name: category.Name, ParentID: category.ParentID}.Validate(db)
+}
+
+// ValidateCategoryMerge controla que la categoría que queda no esté dentro de
+// uno de los duplicados: sus subcategorías pasan a ella y se armaría un ciclo.
+func ValidateCategoryMerge(db *gorm.DB, survivor models.Category, duplicates []models.Category) error {
+	for _, duplicate := range duplicates {
+		if err := (MoveCategoryRequest{ParentID: &survivor.ID}).Validate(db, duplicate.ID); err != nil {
+			return fmt.Errorf("la categoría %d está dentro de la categoría %d y no puede absorberla", survivor.ID, duplicate.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package requests
+
+import (
+	"errors"
+	"time"
+)
+
+type LabelRequest struct {
+	ProductIDs    []uint     `json:"product_ids"`
+	CategoryID    *uint      `json:"category_id"`
+	RepricedSince *time.Time `json:"repriced_since"`
+	Layout        string     `json:"layout"` // hoja predefinida, ej: "a4-3x8"
+	Columns       int        `json:"columns" binding:"omitempty,min=1,max=8"`
+	Rows          int        `json:"rows" binding:"omitempty,min=1,max=30"`
+	LabelHeight   float64    `json:"label_height" binding:"omitempty,gt=0"` // mm, si no se indica se reparte la hoja
+	MarginTop     *float64   `json:"margin_top" binding:"omitempty,gte=0"`
+	MarginLeft    *float64   `json:"margin_left" binding:"omitempty,gte=0"`
+	MarginRight   *float64   `json:"margin_right" binding:"omitempty,gte=0"`
+	Copies        int        `json:"copies" binding:"omitempty,min=1,max=100"`
+	Border        bool       `json:"border"` // dibuja el contorno, útil para imprimir en hoja común
+}
+
+func (r LabelRequest) Validate() error {
+	if len(r.ProductIDs) == 0 && r.CategoryID == nil && r.RepricedSince == nil {
+		return errors.New("indicá productos, una categoría o una fecha de cambio de precio")
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package requests
+
+// MergeRequest indica los registros duplicados que se fusionan en el de la
+// ruta, que es el que queda.
+type MergeRequest struct {
+	DuplicateIDs []uint `json:"duplicate_ids" binding:"required,min=1,dive,gt=0"`
+}
@@ -0,0 +1,10 @@
+package requests
+
+type PricingLineRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type PricingRequest struct {
+	Items []PricingLineRequest `json:"items" binding:"required,min=1,dive"`
+}
@@ -3,6 +3,8 @@ package requests
 import (
 	"fmt"
 	"libreria/models"
+	"sort"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -33,10 +35,13 @@ func (r ProductRequest) UpdateModel(existing models.Product) (models.Product, er
 	existing.Name = r.Name
 	existing.Code = r.Code
 	existing.Sku = r.Sku
-	existing.ProfitMargin = r.ProfitMargin
 	existing.Description = r.Description
-	existing.CategoryID = r.CategoryID
-	existing.BrandID = r.BrandID
+	// Las variantes heredan margen, categoría y marca del padre
+	if !existing.IsVariant() {
+		existing.ProfitMargin = r.ProfitMargin
+		existing.CategoryID = r.CategoryID
+		existing.BrandID = r.BrandID
+	}
 	return existing, nil
 }
 
@@ -53,3 +58,139 @@ func (r ProductRequest) Validate(db *gorm.DB) error {
 	// }
 	return nil
 }
+
+type ProductVariantRequest struct {
+	Code          string            `json:"code" binding:"max=20"` // por defecto, el del producto padre
+	Sku           string            `json:"sku" binding:"required,min=1,max=20"`
+	Name          string            `json:"name" binding:"max=65"` // por defecto, el del padre seguido de los atributos
+	Description   string            `json:"description" binding:"max=150"`
+	Attributes    map[string]string `json:"attributes" binding:"required,min=1"`
+	PriceOverride *float64          `json:"price_override" binding:"omitempty,gt=0"`
+}
+
+// ToModel arma la variante heredando del padre los datos que no se cargan.
+func (r ProductVariantRequest) ToModel(parent models.Product) (models.Product, error) {
+	return r.UpdateModel(parent, models.Product{
+		ParentID:     &parent.ID,
+		ProfitMargin: parent.ProfitMargin,
+		CategoryID:   parent.CategoryID,
+		BrandID:      parent.BrandID,
+	})
+}
+
+func (r ProductVariantRequest) UpdateModel(parent, existing models.Product) (models.Product, error) {
+	attributes := make(map[string]string, len(r.Attributes))
+	for key, value := range r.Attributes {
+		attributes[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	existing.Code = strings.TrimSpace(r.Code)
+	if existing.Code == "" {
+		existing.Code = parent.Code
+	}
+	existing.Sku = strings.TrimSpace(r.Sku)
+	existing.Attributes = attributes
+	existing.PriceOverride = r.PriceOverride
+
+	existing.Name = strings.TrimSpace(r.Name)
+	if existing.Name == "" {
+		existing.Name = variantName(parent.Name, attributes)
+	}
+	if len([]rune(existing.Name)) > 65 {
+		return models.Product{}, fmt.Errorf("el nombre '%s' supera los 65 caracteres, indicá uno más corto", existing.Name)
+	}
+
+	existing.Description = strings.TrimSpace(r.Description)
+	if existing.Description == "" {
+		existing.Description = parent.Description
+	}
+	return existing, nil
+}
+
+// Validate controla los atributos y que el SKU no esté usado por otro producto.
+func (r ProductVariantRequest) Validate(db *gorm.DB, parent models.Product, variantID uint) error {
+	if parent.IsVariant() {
+		return fmt.Errorf("el producto %d es una variante y no puede tener variantes propias", parent.ID)
+	}
+	for key, value := range r.Attributes {
+		if strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("los atributos no pueden tener nombre ni valor vacíos")
+		}
+	}
+
+	var existing models.Product
+	err := db.Where("sku = ? AND id <> ?", strings.TrimSpace(r.Sku), variantID).First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("el SKU '%s' ya está asignado al producto %d", r.Sku, existing.ID)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
+
+// variantName agrega al nombre del padre los valores de los atributos,
+// ordenados por atributo para que el resultado no dependa del pedido.
+func variantName(parentName string, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{parentName}
+	for _, key := range keys {
+		parts = append(parts, attributes[key])
+	}
+	return strings.Join(parts, " ")
+}
+
+type ProductSearchOptions struct {
+	Query string `form:"q" binding:"required,min=2,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ValidateProductRestore controla que sigan existiendo la categoría, la marca
+// y, en las variantes, el producto padre, y que nadie haya tomado el SKU ni sus
+// códigos de barras.
+func ValidateProductRestore(db *gorm.DB, product models.Product) error {
+	if err := (ProductRequest{CategoryID: product.CategoryID}).Validate(db); err != nil {
+		return err
+	}
+
+	var brand models.Brand
+	if err := db.First(&brand, product.BrandID).Error; err != nil {
+		return fmt.Errorf("marca con ID %d no encontrada", product.BrandID)
+	}
+
+	if product.IsVariant() {
+		var parent models.Product
+		if err := db.First(&parent, *product.ParentID).Error; err != nil {
+			return fmt.Errorf("el producto padre %d está eliminado; hay que restaurarlo primero", *product.ParentID)
+		}
+	}
+
+	// Mientras estuvo en la papelera otro producto pudo tomar sus códigos de barras
+	var barcodes []models.ProductBarcode
+	if err := db.Where("product_id = ?", product.ID).Find(&barcodes).Error; err != nil {
+		return err
+	}
+	for _, barcode := range barcodes {
+		if err := ValidateBarcodeAvailable(db, barcode.Code, product.ID); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(product.Sku) == "" {
+		return nil
+	}
+	var existing models.Product
+	err := db.Where("sku = ? AND id <> ?", product.Sku, product.ID).First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("el SKU '%s' ya está asignado al producto %d", product.Sku, existing.ID)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
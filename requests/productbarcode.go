@@ -0,0 +1,43 @@
+package requests
+
+import (
+	"fmt"
+	"libreria/models"
+	"libreria/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ProductBarcodeRequest struct {
+	Code string `json:"code" binding:"required,min=8,max=13"`
+}
+
+func (r ProductBarcodeRequest) ToModel() (models.ProductBarcode, error) {
+	return models.ProductBarcode{Code: strings.TrimSpace(r.Code)}, nil
+}
+
+func (r ProductBarcodeRequest) Validate(db *gorm.DB) error {
+	code := strings.TrimSpace(r.Code)
+	if err := utils.ValidateBarcode(code); err != nil {
+		return err
+	}
+
+	return ValidateBarcodeAvailable(db, code, 0)
+}
+
+// ValidateBarcodeAvailable controla que ningún otro producto vigente use el
+// código. Los códigos de productos en la papelera no cuentan.
+func ValidateBarcodeAvailable(db *gorm.DB, code string, productID uint) error {
+	var existing models.ProductBarcode
+	err := db.Joins("INNER JOIN products ON products.id = product_barcodes.product_id AND products.deleted_at IS NULL").
+		Where("product_barcodes.code IN ? AND product_barcodes.product_id <> ?", utils.BarcodeVariants(code), productID).
+		First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("el código de barras %s ya está asignado al producto %d", code, existing.ProductID)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package requests
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type BundleComponentRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type ProductBundleRequest struct {
+	Pricing    string                   `json:"pricing" binding:"required,oneof=fixed components"`
+	FixedPrice float64                  `json:"fixed_price" binding:"gte=0"`
+	Discount   float64                  `json:"discount" binding:"gte=0,lt=100"`
+	Components []BundleComponentRequest `json:"components" binding:"required,min=1,dive"`
+}
+
+func (r ProductBundleRequest) ToModel(productID uint) (models.ProductBundle, error) {
+	return r.UpdateModel(models.ProductBundle{ProductID: productID})
+}
+
+// UpdateModel reemplaza la composición completa del combo.
+func (r ProductBundleRequest) UpdateModel(existing models.ProductBundle) (models.ProductBundle, error) {
+	existing.Pricing = r.Pricing
+	existing.FixedPrice = 0
+	existing.Discount = 0
+	if r.Pricing == string(constants.BUNDLE_PRICING_FIXED) {
+		existing.FixedPrice = r.FixedPrice
+	} else {
+		existing.Discount = r.Discount
+	}
+
+	existing.Components = make([]models.BundleComponent, 0, len(r.Components))
+	for _, component := range r.Components {
+		existing.Components = append(existing.Components, models.BundleComponent{
+			ProductID: component.ProductID,
+			Quantity:  component.Quantity,
+		})
+	}
+	return existing, nil
+}
+
+// Validate controla que los componentes existan, no se repitan y no sean a su
+// vez combos: la composición tiene un solo nivel.
+func (r ProductBundleRequest) Validate(db *gorm.DB, productID uint) error {
+	if r.Pricing == string(constants.BUNDLE_PRICING_FIXED) && r.FixedPrice <= 0 {
+		return fmt.Errorf("el precio fijo del combo debe ser mayor a 0")
+	}
+
+	var count int64
+	err := db.Model(&models.BundleComponent{}).
+		Joins("INNER JOIN product_bundles bundle ON bundle.id = bundle_components.bundle_id AND bundle.deleted_at IS NULL").
+		Where("bundle_components.product_id = ?", productID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("el producto %d es componente de otro combo y no puede ser un combo", productID)
+	}
+
+	seen := map[uint]bool{}
+	for _, component := range r.Components {
+		if component.ProductID == productID {
+			return fmt.Errorf("el combo no puede incluirse a sí mismo")
+		}
+		if seen[component.ProductID] {
+			return fmt.Errorf("el producto %d está repetido en el combo, sumá las cantidades", component.ProductID)
+		}
+		seen[component.ProductID] = true
+
+		var product models.Product
+		if err := db.First(&product, component.ProductID).Error; err != nil {
+			return fmt.Errorf("producto con ID %d no encontrado", component.ProductID)
+		}
+
+		var bundle models.ProductBundle
+		err := db.Where("product_id = ?", component.ProductID).First(&bundle).Error
+		if err == nil {
+			return fmt.Errorf("el producto '%s' es un combo y no puede ser componente de otro", product.Name)
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+	}
+	return nil
+}
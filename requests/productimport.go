@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+type ProductImportOptions struct {
+	DryRun bool   `form:"dry_run"`                                      // valida y devuelve el reporte sin guardar nada
+	Mode   string `form:"mode" binding:"omitempty,oneof=create upsert"` // "upsert" actualiza los productos existentes
+	// Crea las categorías y marcas que no existan en lugar de marcar la fila con error
+	CreateMissing bool `form:"create_missing"`
+	// "xlsx" o "csv"; si no se indica se toma de la extensión del archivo
+	Format string `form:"format" binding:"omitempty,oneof=xlsx csv"`
+	CSVOptions
+}
+
+type ProductExportOptions struct {
+	CategoryID *uint  `form:"category_id"`
+	BrandID    *uint  `form:"brand_id"`
+	Template   bool   `form:"template"` // planilla vacía con una fila de ejemplo
+	Format     string `form:"format" binding:"omitempty,oneof=xlsx csv"`
+	CSVOptions
+}
+
+// CSVOptions indica cómo leer o escribir un CSV. Se ignora para Excel.
+type CSVOptions struct {
+	Delimiter    string `form:"delimiter" binding:"omitempty,oneof=comma semicolon pipe tab"`
+	Encoding     string `form:"encoding" binding:"omitempty,oneof=utf-8 windows-1252"`
+	DecimalComma bool   `form:"decimal_comma"` // "1.234,56" en lugar de "1234.56"
+}
+
+func (o ProductImportOptions) IsUpsert() bool {
+	return o.Mode == "upsert"
+}
+
+func (o ProductImportOptions) IsCSV() bool {
+	return o.Format == "csv"
+}
+
+// DetectFormat completa el formato a partir del nombre del archivo subido.
+func (o *ProductImportOptions) DetectFormat(fileName string) {
+	if o.Format != "" {
+		return
+	}
+	o.Format = "xlsx"
+	if strings.EqualFold(filepath.Ext(fileName), ".csv") {
+		o.Format = "csv"
+	}
+}
+
+func (o ProductExportOptions) IsCSV() bool {
+	return o.Format == "csv"
+}
+
+func (o CSVOptions) Comma() rune {
+	switch o.Delimiter {
+	case "semicolon":
+		return ';'
+	case "pipe":
+		return '|'
+	case "tab":
+		return '\t'
+	default:
+		return ','
+	}
+}
+
+func (o CSVOptions) IsWindows1252() bool {
+	return o.Encoding == "windows-1252"
+}
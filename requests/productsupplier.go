@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"fmt"
+	"libreria/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductSupplierRequest struct {
+	ProductID    uint    `json:"product_id" binding:"required"`
+	SupplierID   uint    `json:"supplier_id" binding:"required"`
+	SupplierCode string  `json:"supplier_code" binding:"max=30"`
+	LastCost     float64 `json:"last_cost" binding:"gte=0"`
+	PackSize     int     `json:"pack_size" binding:"omitempty,min=1"`
+	LeadTimeDays int     `json:"lead_time_days" binding:"gte=0"`
+	Preferred    bool    `json:"preferred"`
+}
+
+func (r ProductSupplierRequest) ToModel() (models.ProductSupplier, error) {
+	return r.UpdateModel(models.ProductSupplier{})
+}
+
+func (r ProductSupplierRequest) UpdateModel(existing models.ProductSupplier) (models.ProductSupplier, error) {
+	if r.LastCost != existing.LastCost {
+		now := time.Now()
+		existing.LastCostAt = &now
+	}
+	existing.ProductID = r.ProductID
+	existing.SupplierID = r.SupplierID
+	existing.SupplierCode = r.SupplierCode
+	existing.LastCost = r.LastCost
+	existing.PackSize = r.PackSize
+	if existing.PackSize == 0 {
+		existing.PackSize = 1
+	}
+	existing.LeadTimeDays = r.LeadTimeDays
+	existing.Preferred = r.Preferred
+	return existing, nil
+}
+
+func (r ProductSupplierRequest) Validate(db *gorm.DB) error {
+	return r.validate(db, 0)
+}
+
+func (r ProductSupplierRequest) ValidateUpdate(db *gorm.DB, existing models.ProductSupplier) error {
+	return r.validate(db, existing.ID)
+}
+
+func (r ProductSupplierRequest) validate(db *gorm.DB, id uint) error {
+	var product models.Product
+	if err := db.First(&product, r.ProductID).Error; err != nil {
+		return fmt.Errorf("producto con ID %d no encontrado", r.ProductID)
+	}
+	var supplier models.Supplier
+	if err := db.First(&supplier, r.SupplierID).Error; err != nil {
+		return fmt.Errorf("proveedor con ID %d no encontrado", r.SupplierID)
+	}
+
+	var count int64
+	err := db.Model(&models.ProductSupplier{}).
+		Where("product_id = ? AND supplier_id = ? AND id <> ?", r.ProductID, r.SupplierID, id).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("el producto ya está asociado al proveedor '%s'", supplier.Name)
+	}
+	return nil
+}
+
+type ReorderSuggestionOptions struct {
+	SupplierID   *uint `form:"supplier_id"`
+	HistoryDays  int   `form:"history_days" binding:"omitempty,min=1,max=365"`  // ventas que se toman para el promedio diario
+	CoverageDays int   `form:"coverage_days" binding:"omitempty,min=1,max=365"` // días que tiene que cubrir el pedido después de la entrega
+}
@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRequest struct {
+	Name          string    `json:"name" binding:"required,min=1,max=65"`
+	Type          string    `json:"type" binding:"required,oneof=percentage fixed buy_x_pay_y"`
+	Scope         string    `json:"scope" binding:"required,oneof=product category brand order"`
+	ScopeID       *uint     `json:"scope_id"`
+	Value         float64   `json:"value" binding:"gte=0"`
+	BuyQuantity   int       `json:"buy_quantity" binding:"gte=0"`
+	PayQuantity   int       `json:"pay_quantity" binding:"gte=0"`
+	MinOrderTotal float64   `json:"min_order_total" binding:"gte=0"`
+	StartsAt      time.Time `json:"starts_at" binding:"required"`
+	EndsAt        time.Time `json:"ends_at" binding:"required"`
+	IsActive      *bool     `json:"is_active"`
+}
+
+func (r PromotionRequest) ToModel() (models.Promotion, error) {
+	promotion := models.Promotion{IsActive: true}
+	return r.UpdateModel(promotion)
+}
+
+func (r PromotionRequest) UpdateModel(existing models.Promotion) (models.Promotion, error) {
+	existing.Name = r.Name
+	existing.Type = r.Type
+	existing.Scope = r.Scope
+	existing.ScopeID = r.ScopeID
+	existing.Value = r.Value
+	existing.BuyQuantity = r.BuyQuantity
+	existing.PayQuantity = r.PayQuantity
+	existing.MinOrderTotal = r.MinOrderTotal
+	existing.StartsAt = r.StartsAt
+	existing.EndsAt = r.EndsAt
+	if r.IsActive != nil {
+		existing.IsActive = *r.IsActive
+	}
+	if existing.Scope == string(constants.PROMOTION_SCOPE_ORDER) {
+		existing.ScopeID = nil
+	}
+	return existing, nil
+}
+
+func (r PromotionRequest) Validate(db *gorm.DB) error {
+	if !r.EndsAt.After(r.StartsAt) {
+		return errors.New("la fecha de fin debe ser posterior a la de inicio")
+	}
+
+	switch constants.PromotionType(r.Type) {
+	case constants.PROMOTION_TYPE_PERCENTAGE:
+		if r.Value <= 0 || r.Value > 100 {
+			return errors.New("el porcentaje de descuento debe estar entre 0 y 100")
+		}
+	case constants.PROMOTION_TYPE_FIXED:
+		if r.Value <= 0 {
+			return errors.New("el monto de descuento debe ser mayor a 0")
+		}
+	case constants.PROMOTION_TYPE_BUY_X_PAY_Y:
+		if r.PayQuantity <= 0 || r.BuyQuantity <= r.PayQuantity {
+			return errors.New("en un NxM la cantidad a llevar debe ser mayor a la cantidad a pagar")
+		}
+		if r.Scope == string(constants.PROMOTION_SCOPE_ORDER) {
+			return errors.New("un NxM no puede aplicarse al total del pedido")
+		}
+	}
+
+	if r.Scope == string(constants.PROMOTION_SCOPE_ORDER) {
+		return nil
+	}
+
+	if r.ScopeID == nil {
+		return fmt.Errorf("scope_id es obligatorio para el alcance '%s'", r.Scope)
+	}
+
+	var count int64
+	var err error
+	switch constants.PromotionScope(r.Scope) {
+	case constants.PROMOTION_SCOPE_PRODUCT:
+		err = db.Model(&models.Product{}).Where("id = ?", *r.ScopeID).Count(&count).Error
+	case constants.PROMOTION_SCOPE_CATEGORY:
+		err = db.Model(&models.Category{}).Where("id = ?", *r.ScopeID).Count(&count).Error
+	case constants.PROMOTION_SCOPE_BRAND:
+		err = db.Model(&models.Brand{}).Where("id = ?", *r.ScopeID).Count(&count).Error
+	}
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no existe el elemento %d para el alcance '%s'", *r.ScopeID, r.Scope)
+	}
+	return nil
+}
+
+func (r PromotionRequest) ValidateUpdate(db *gorm.DB, existing models.Promotion) error {
+	return r.Validate(db)
+}
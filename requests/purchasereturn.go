@@ -0,0 +1,54 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseReturnItemRequest struct {
+	PurchaseHistoryID uint `json:"purchase_history_id" binding:"required"`
+	Quantity          int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type PurchaseReturnRequest struct {
+	Reason string                      `json:"reason" binding:"required,min=1,max=150"`
+	Items  []PurchaseReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (r PurchaseReturnRequest) ToModel() (models.PurchaseReturn, error) {
+	items := make([]models.PurchaseReturnItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, models.PurchaseReturnItem{
+			PurchaseHistoryID: item.PurchaseHistoryID,
+			Quantity:          item.Quantity,
+		})
+	}
+	return models.PurchaseReturn{
+		Reason: r.Reason,
+		Items:  items,
+	}, nil
+}
+
+func (r PurchaseReturnRequest) Validate(db *gorm.DB) error {
+	seen := map[uint]bool{}
+	var supplierID uint
+	for _, item := range r.Items {
+		if seen[item.PurchaseHistoryID] {
+			return fmt.Errorf("la compra %d está repetida en la devolución", item.PurchaseHistoryID)
+		}
+		seen[item.PurchaseHistoryID] = true
+
+		var purchase models.PurchaseHistory
+		if err := db.First(&purchase, item.PurchaseHistoryID).Error; err != nil {
+			return fmt.Errorf("compra con ID %d no encontrada", item.PurchaseHistoryID)
+		}
+		if supplierID != 0 && purchase.SupplierID != supplierID {
+			return errors.New("todas las compras de una devolución deben ser del mismo proveedor")
+		}
+		supplierID = purchase.SupplierID
+	}
+	return nil
+}
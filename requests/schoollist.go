@@ -0,0 +1,111 @@
+package requests
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type SchoolListLineRequest struct {
+	Text       string `json:"text" binding:"required,max=150"`
+	Quantity   int    `json:"quantity" binding:"required,gt=0"`
+	ProductID  *uint  `json:"product_id"`
+	CategoryID *uint  `json:"category_id"`
+}
+
+type SchoolListRequest struct {
+	School string                  `json:"school" binding:"required,max=100"`
+	Grade  string                  `json:"grade" binding:"required,max=50"`
+	Year   int                     `json:"year" binding:"required,gte=2000,lte=2100"`
+	Lines  []SchoolListLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+func (r SchoolListRequest) ToModel() (models.SchoolList, error) {
+	return r.UpdateModel(models.SchoolList{})
+}
+
+// UpdateModel reemplaza todas las líneas de la lista.
+func (r SchoolListRequest) UpdateModel(existing models.SchoolList) (models.SchoolList, error) {
+	existing.School = strings.TrimSpace(r.School)
+	existing.Grade = strings.TrimSpace(r.Grade)
+	existing.Year = r.Year
+	existing.Lines = make([]models.SchoolListLine, 0, len(r.Lines))
+	for i, line := range r.Lines {
+		existing.Lines = append(existing.Lines, models.SchoolListLine{
+			Position:   i + 1,
+			Text:       strings.TrimSpace(line.Text),
+			Quantity:   line.Quantity,
+			ProductID:  line.ProductID,
+			CategoryID: line.CategoryID,
+		})
+	}
+	return existing, nil
+}
+
+func (r SchoolListRequest) Validate(db *gorm.DB) error {
+	for i, line := range r.Lines {
+		mapping := SchoolListLineMappingRequest{ProductID: line.ProductID, CategoryID: line.CategoryID}
+		if err := mapping.Validate(db); err != nil {
+			return fmt.Errorf("línea %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// SchoolListLineMappingRequest asocia una línea a un producto o a una
+// categoría. Sin ninguno de los dos, la línea queda sin asignar.
+type SchoolListLineMappingRequest struct {
+	ProductID  *uint `json:"product_id"`
+	CategoryID *uint `json:"category_id"`
+}
+
+func (r SchoolListLineMappingRequest) UpdateModel(existing models.SchoolListLine) (models.SchoolListLine, error) {
+	existing.ProductID = r.ProductID
+	existing.CategoryID = r.CategoryID
+	return existing, nil
+}
+
+func (r SchoolListLineMappingRequest) Validate(db *gorm.DB) error {
+	if r.ProductID != nil && r.CategoryID != nil {
+		return fmt.Errorf("indicá un producto o una categoría, no ambos")
+	}
+	if r.ProductID != nil {
+		var product models.Product
+		if err := db.First(&product, *r.ProductID).Error; err != nil {
+			return fmt.Errorf("producto con ID %d no encontrado", *r.ProductID)
+		}
+	}
+	if r.CategoryID != nil {
+		var category models.Category
+		if err := db.First(&category, *r.CategoryID).Error; err != nil {
+			return fmt.Errorf("categoría con ID %d no encontrada", *r.CategoryID)
+		}
+	}
+	return nil
+}
+
+// SchoolListGenerateRequest indica si la lista se convierte en presupuesto
+// (a nombre de ClientName) o en venta (al cliente CustomerID).
+type SchoolListGenerateRequest struct {
+	Target     string `json:"target" binding:"required,oneof=budget sell"`
+	ClientName string `json:"client_name" binding:"max=100"`
+	CustomerID uint   `json:"customer_id"`
+}
+
+func (r SchoolListGenerateRequest) Validate(db *gorm.DB) error {
+	if r.Target == string(constants.SCHOOL_LIST_TARGET_BUDGET) {
+		if strings.TrimSpace(r.ClientName) == "" {
+			return fmt.Errorf("el nombre del cliente es obligatorio para el presupuesto")
+		}
+		return nil
+	}
+
+	var customer models.Customer
+	if err := db.First(&customer, r.CustomerID).Error; err != nil {
+		return fmt.Errorf("cliente con ID %d no encontrado", r.CustomerID)
+	}
+	return nil
+}
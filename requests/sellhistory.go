@@ -7,6 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// SellHistoryRequestArray son las líneas de una misma venta.
+type SellHistoryRequestArray []SellHistoryRequest
+
 type SellHistoryRequest struct {
 	ProductID  uint `json:"product_id" binding:"required"`
 	CustomerID uint `json:"customer_id" binding:"required"`
@@ -0,0 +1,56 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"libreria/models"
+
+	"gorm.io/gorm"
+)
+
+type SellReturnItemRequest struct {
+	SellHistoryID uint `json:"sell_history_id" binding:"required"`
+	Quantity      int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type SellReturnRequest struct {
+	Reason     string                  `json:"reason" binding:"required,min=1,max=150"`
+	Settlement string                  `json:"settlement" binding:"required,oneof=refund credit"`
+	Items      []SellReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (r SellReturnRequest) ToModel() (models.SellReturn, error) {
+	items := make([]models.SellReturnItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, models.SellReturnItem{
+			SellHistoryID: item.SellHistoryID,
+			Quantity:      item.Quantity,
+		})
+	}
+	return models.SellReturn{
+		Reason:     r.Reason,
+		Settlement: r.Settlement,
+		Items:      items,
+	}, nil
+}
+
+func (r SellReturnRequest) Validate(db *gorm.DB) error {
+	seen := map[uint]bool{}
+	var customerID uint
+	for _, item := range r.Items {
+		if seen[item.SellHistoryID] {
+			return fmt.Errorf("la venta %d está repetida en la devolución", item.SellHistoryID)
+		}
+		seen[item.SellHistoryID] = true
+
+		var sell models.SellHistory
+		if err := db.First(&sell, item.SellHistoryID).Error; err != nil {
+			return fmt.Errorf("venta con ID %d no encontrada", item.SellHistoryID)
+		}
+		if customerID != 0 && sell.CustomerID != customerID {
+			return errors.New("todas las ventas de una devolución deben ser del mismo cliente")
+		}
+		customerID = sell.CustomerID
+	}
+	return nil
+}
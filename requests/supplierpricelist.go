@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"errors"
+	"libreria/models"
+)
+
+type SupplierPriceListMappingRequest struct {
+	Sheet             string `json:"sheet" binding:"max=65"`
+	HeaderRow         int    `json:"header_row" binding:"omitempty,min=1"`
+	CodeColumn        string `json:"code_column" binding:"max=65"`
+	BarcodeColumn     string `json:"barcode_column" binding:"max=65"`
+	SkuColumn         string `json:"sku_column" binding:"max=65"`
+	DescriptionColumn string `json:"description_column" binding:"max=65"`
+	CostColumn        string `json:"cost_column" binding:"required,max=65"`
+	Delimiter         string `json:"delimiter" binding:"omitempty,oneof=comma semicolon pipe tab"`
+	Encoding          string `json:"encoding" binding:"omitempty,oneof=utf-8 windows-1252"`
+	DecimalComma      bool   `json:"decimal_comma"`
+}
+
+func (r SupplierPriceListMappingRequest) Validate() error {
+	if r.CodeColumn == "" && r.BarcodeColumn == "" && r.SkuColumn == "" {
+		return errors.New("indicá al menos una columna para identificar el producto: código, código de barras o SKU")
+	}
+	return nil
+}
+
+func (r SupplierPriceListMappingRequest) UpdateModel(existing models.SupplierPriceListMapping) models.SupplierPriceListMapping {
+	existing.Sheet = r.Sheet
+	existing.HeaderRow = r.HeaderRow
+	if existing.HeaderRow == 0 {
+		existing.HeaderRow = 1
+	}
+	existing.CodeColumn = r.CodeColumn
+	existing.BarcodeColumn = r.BarcodeColumn
+	existing.SkuColumn = r.SkuColumn
+	existing.DescriptionColumn = r.DescriptionColumn
+	existing.CostColumn = r.CostColumn
+	existing.Delimiter = r.Delimiter
+	existing.Encoding = r.Encoding
+	existing.DecimalComma = r.DecimalComma
+	return existing
+}
+
+type ApplySupplierPriceListRequest struct {
+	// Ítems a aplicar; si está vacío se aplican todos los que cambian el costo
+	ItemIDs []uint `json:"item_ids"`
+	// Crea un precio nuevo en la lista de precios con el costo nuevo más el margen del producto
+	Reprice bool `json:"reprice"`
+}
@@ -0,0 +1,9 @@
+package responses
+
+import "libreria/models"
+
+type CustomerAccountResponse struct {
+	CustomerID uint                             `json:"customer_id"`
+	Balance    float64                          `json:"balance"`
+	Movements  []models.CustomerAccountMovement `json:"movements"`
+}
@@ -0,0 +1,25 @@
+package responses
+
+type AppliedDiscount struct {
+	PromotionID uint    `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Amount      float64 `json:"amount"`
+}
+
+type PricedLine struct {
+	ProductID   uint              `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Quantity    int               `json:"quantity"`
+	UnitPrice   float64           `json:"unit_price"` // precio de lista
+	Subtotal    float64           `json:"subtotal"`
+	Discount    float64           `json:"discount"`
+	Total       float64           `json:"total"`
+	Discounts   []AppliedDiscount `json:"discounts"`
+}
+
+type PricingResponse struct {
+	Lines    []PricedLine `json:"lines"`
+	Subtotal float64      `json:"subtotal"`
+	Discount float64      `json:"discount"`
+	Total    float64      `json:"total"`
+}
@@ -9,4 +9,30 @@ type ProductResponse struct {
 	Description  string  `json:"description"`
 	CategoryName string  `json:"category_name"`
 	BrandName    string  `json:"brand_name"`
+	// Datos de variante; en el listado las variantes van dentro de su padre
+	ParentID      *uint             `json:"parent_id,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty" gorm:"serializer:json"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Variants      []ProductResponse `json:"variants,omitempty" gorm:"-"`
+}
+
+type ProductExportRow struct {
+	ProductResponse
+	Stock        int     `json:"stock"`
+	LastCost     float64 `json:"last_cost"`
+	LastSupplier string  `json:"last_supplier"`
+	Price        float64 `json:"price" gorm:"-"`
+}
+
+type ProductLookupResponse struct {
+	ProductResponse
+	Barcode string  `json:"barcode"`
+	Stock   int     `json:"stock"`
+	Price   float64 `json:"price"`
+}
+
+type ProductSearchResult struct {
+	ProductResponse
+	Stock int     `json:"stock"`
+	Rank  float64 `json:"rank"`
 }
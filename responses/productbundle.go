@@ -0,0 +1,9 @@
+package responses
+
+import "libreria/models"
+
+type ProductBundleResponse struct {
+	models.ProductBundle
+	Price     float64 `json:"price"`
+	Available int     `json:"available"` // combos que se pueden armar con el stock actual
+}
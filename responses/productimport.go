@@ -0,0 +1,41 @@
+package responses
+
+type ProductImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Cell    string `json:"cell"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ProductImportPreview struct {
+	Row          int     `json:"row"`
+	ID           uint    `json:"id,omitempty"` // producto existente que se actualiza
+	Code         string  `json:"code"`
+	Sku          string  `json:"sku"`
+	Name         string  `json:"name"`
+	ProfitMargin float64 `json:"profit_margin"`
+	Description  string  `json:"description"`
+	CategoryName string  `json:"category_name"`
+	BrandName    string  `json:"brand_name"`
+	// Saldo inicial, solo para productos nuevos
+	OpeningStock int     `json:"opening_stock,omitempty"`
+	OpeningCost  float64 `json:"opening_cost,omitempty"`
+	SupplierName string  `json:"supplier_name,omitempty"`
+}
+
+type ProductImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	TotalRows int                     `json:"total_rows"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	Failed    int                     `json:"failed"` // filas con al menos un error
+	Errors    []ProductImportRowError `json:"errors"`
+	ToCreate  []ProductImportPreview  `json:"to_create"`
+	ToUpdate  []ProductImportPreview  `json:"to_update"`
+	// Categorías, marcas y proveedores creados (o a crear, en una prueba) por la importación
+	NewCategories []string `json:"new_categories"`
+	NewBrands     []string `json:"new_brands"`
+	NewSuppliers  []string `json:"new_suppliers"`
+}
@@ -0,0 +1,17 @@
+package responses
+
+type ReorderSuggestion struct {
+	ProductID     uint    `json:"product_id"`
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	Stock         int     `json:"stock"`
+	DailySales    float64 `json:"daily_sales"`
+	SupplierID    uint    `json:"supplier_id"`
+	SupplierName  string  `json:"supplier_name"`
+	SupplierCode  string  `json:"supplier_code"`
+	PackSize      int     `json:"pack_size"`
+	LeadTimeDays  int     `json:"lead_time_days"`
+	LastCost      float64 `json:"last_cost"`
+	Quantity      int     `json:"quantity"` // múltiplo del bulto
+	EstimatedCost float64 `json:"estimated_cost"`
+}
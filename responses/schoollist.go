@@ -0,0 +1,19 @@
+package responses
+
+import "libreria/models"
+
+type SchoolListLineResult struct {
+	LineID      uint   `json:"line_id"`
+	Text        string `json:"text"`
+	Quantity    int    `json:"quantity"`
+	Status      string `json:"status"` // "matched", "unmapped" o "out_of_stock"
+	ProductID   uint   `json:"product_id,omitempty"`
+	ProductName string `json:"product_name,omitempty"`
+}
+
+type SchoolListResult struct {
+	Budget  *models.Budget         `json:"budget,omitempty"`
+	Sells   []models.SellHistory   `json:"sells,omitempty"`
+	Lines   []SchoolListLineResult `json:"lines"`
+	Missing int                    `json:"missing"` // líneas que no se pudieron cubrir
+}
@@ -0,0 +1,9 @@
+package responses
+
+import "libreria/models"
+
+type SupplierAccountResponse struct {
+	SupplierID uint                             `json:"supplier_id"`
+	Balance    float64                          `json:"balance"`
+	Movements  []models.SupplierAccountMovement `json:"movements"`
+}
@@ -0,0 +1,8 @@
+package responses
+
+import "libreria/models"
+
+type SupplierPriceListBatchResponse struct {
+	models.SupplierPriceListBatch
+	Counts map[string]int `json:"counts"` // ítems por estado
+}
@@ -9,6 +9,8 @@ import (
 	"libreria/repositories"
 	"libreria/requests"
 	"libreria/services"
+	"libreria/storage"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,12 +18,48 @@ import (
 
 func SetupRoutes(r *gin.Engine, app *app.App) {
 
+	fileStorage, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	trash, err := common.NewTrashFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Common operations
-	categoryOps := common.NewGormOperations[models.Category](app.DB)
-	brandOps := common.NewGormOperations[models.Brand](app.DB)
-	productOps := common.NewGormOperations[models.Product](app.DB)
-	supplierOps := common.NewGormOperations[models.Supplier](app.DB)
-	customerdOps := common.NewGormOperations[models.Customer](app.DB)
+	categoryOps := common.NewGormOperations[models.Category](app.DB).WithReferences(
+		common.Reference{Name: "productos", Model: &models.Product{}, Column: "category_id", Reassignable: true},
+		common.Reference{Name: "subcategorías", Model: &models.Category{}, Column: "parent_id"}, // se mueven con /move, que evita ciclos
+		common.Reference{Name: "promociones", Model: &models.Promotion{}, Column: "scope_id", Filter: "scope = 'category'", Reassignable: true},
+		common.Reference{Name: "líneas de listas escolares", Model: &models.SchoolListLine{}, Column: "category_id", Reassignable: true},
+	).WithRestoreValidation(requests.ValidateCategoryRestore).WithMergeValidation(requests.ValidateCategoryMerge)
+	brandOps := common.NewGormOperations[models.Brand](app.DB).WithReferences(
+		common.Reference{Name: "productos", Model: &models.Product{}, Column: "brand_id", Reassignable: true},
+		common.Reference{Name: "promociones", Model: &models.Promotion{}, Column: "scope_id", Filter: "scope = 'brand'", Reassignable: true},
+	).WithRestoreValidation(requests.ValidateBrandRestore)
+	productOps := common.NewGormOperations[models.Product](app.DB).WithReferences(
+		common.Reference{Name: "ventas", Model: &models.SellHistory{}, Column: "product_id"},
+		common.Reference{Name: "compras", Model: &models.PurchaseHistory{}, Column: "product_id"},
+		common.Reference{Name: "unidades en stock", Model: &models.ProductStock{}, Column: "product_id", Filter: "quantity <> 0"},
+		common.Reference{Name: "variantes", Model: &models.Product{}, Column: "parent_id"},
+		common.Reference{Name: "combos que lo incluyen", Model: &models.BundleComponent{}, Column: "product_id"},
+		common.Reference{Name: "promociones", Model: &models.Promotion{}, Column: "scope_id", Filter: "scope = 'product'", Reassignable: true},
+		common.Reference{Name: "líneas de listas escolares", Model: &models.SchoolListLine{}, Column: "product_id", Reassignable: true},
+	).WithRestoreValidation(requests.ValidateProductRestore)
+	supplierOps := common.NewGormOperations[models.Supplier](app.DB).WithReferences(
+		common.Reference{Name: "compras", Model: &models.PurchaseHistory{}, Column: "supplier_id"},
+		common.Reference{Name: "devoluciones a proveedor", Model: &models.PurchaseReturn{}, Column: "supplier_id"},
+		common.Reference{Name: "movimientos de cuenta corriente", Model: &models.SupplierAccountMovement{}, Column: "supplier_id"},
+		common.Reference{Name: "productos del catálogo del proveedor", Model: &models.ProductSupplier{}, Column: "supplier_id", Unique: []string{"product_id", "supplier_id"}},
+		common.Reference{Name: "configuración de listas de precios", Model: &models.SupplierPriceListMapping{}, Column: "supplier_id", MergeOnly: true, Unique: []string{"supplier_id"}},
+		common.Reference{Name: "listas de precios", Model: &models.SupplierPriceListBatch{}, Column: "supplier_id", MergeOnly: true},
+	)
+	customerdOps := common.NewGormOperations[models.Customer](app.DB).WithReferences(
+		common.Reference{Name: "ventas", Model: &models.SellHistory{}, Column: "customer_id"},
+		common.Reference{Name: "devoluciones", Model: &models.SellReturn{}, Column: "customer_id"},
+		common.Reference{Name: "movimientos de cuenta corriente", Model: &models.CustomerAccountMovement{}, Column: "customer_id"},
+	)
 	// Repositorios
 	dashboardRepo := repositories.NewDashboardRepository(app.DB)
 	productRepo := repositories.NewProductRepository(app.DB)
@@ -29,27 +67,64 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 	purchaseRepo := repositories.NewPurchaseHistoryRepository(app.DB)
 	sellRepo := repositories.NewSellHistoryRepository(app.DB)
 	stockMovementRepo := repositories.NewStockMovementRepository(app.DB)
+	sellReturnRepo := repositories.NewSellReturnRepository(app.DB)
+	customerAccountRepo := repositories.NewCustomerAccountRepository(app.DB)
+	purchaseReturnRepo := repositories.NewPurchaseReturnRepository(app.DB)
+	supplierAccountRepo := repositories.NewSupplierAccountRepository(app.DB)
+	importJobRepo := repositories.NewImportJobRepository(app.DB)
+	productSupplierRepo := repositories.NewProductSupplierRepository(app.DB)
+	supplierPriceListRepo := repositories.NewSupplierPriceListRepository(app.DB)
+	productImageRepo := repositories.NewProductImageRepository(app.DB)
+	productBundleRepo := repositories.NewProductBundleRepository(app.DB)
+	schoolListRepo := repositories.NewSchoolListRepository(app.DB)
+	categoryRepo := repositories.NewCategoryRepository(app.DB)
 	// Servicios
-	productService := services.NewProductService(app.DB, productRepo, categoryOps, brandOps)
+	pricingService := services.NewPricingService(app.DB)
+	categoryService := services.NewCategoryService(app.DB, categoryRepo, pricingService)
+	productService := services.NewProductService(app.DB, productRepo, categoryOps, brandOps, supplierOps, pricingService)
 	productStockService := services.NewProductStockService(app.DB, productStockRepo)
 	stockMovementService := services.NewStockMovementService(app.DB, stockMovementRepo)
 	purchaseService := services.NewPurchaseHistoryService(app.DB, purchaseRepo, productStockRepo, stockMovementRepo, productStockService, stockMovementService)
 	sellService := services.NewSellHistoryService(app.DB, sellRepo, productStockRepo, stockMovementRepo, productStockService, stockMovementService)
 	dashboardService := services.NewDashboardService(app.DB, dashboardRepo, supplierOps, customerdOps, productOps)
-	budgetService := services.NewBudgetService(app.DB)
+	productImageService := services.NewProductImageService(app.DB, productImageRepo, fileStorage)
+	productBundleService := services.NewProductBundleService(app.DB, productBundleRepo, pricingService)
+	budgetService := services.NewBudgetService(app.DB, pricingService, productImageService)
+	schoolListService := services.NewSchoolListService(app.DB, schoolListRepo, pricingService, budgetService, sellService)
+	labelService := services.NewLabelService(app.DB, productRepo, pricingService)
+	sellReturnService := services.NewSellReturnService(app.DB, sellReturnRepo, customerAccountRepo)
+	purchaseReturnService := services.NewPurchaseReturnService(app.DB, purchaseReturnRepo, supplierAccountRepo)
+	importJobService := services.NewImportJobService(app.DB, importJobRepo, productService)
+	importJobService.Start()
+	supplierPriceListService := services.NewSupplierPriceListService(app.DB, supplierPriceListRepo, productSupplierRepo, productRepo)
+	productSupplierService := services.NewProductSupplierService(app.DB, productSupplierRepo)
 
 	// Controladores
-	productController := controllers.NewProductController(productService)
+
+	categoryController := controllers.NewCategoryController(categoryService)
+	productController := controllers.NewProductController(productService, importJobService)
+	importJobController := controllers.NewImportJobController(importJobService)
 	purchaseController := controllers.NewPurchaseHistoryController(purchaseService)
 	sellController := controllers.NewSellHistoryControllerController(sellService)
 	dashboardController := controllers.NewDashboardController(dashboardService)
 	budgetController := controllers.NewBudgetController(budgetService)
+	sellReturnController := controllers.NewSellReturnController(sellReturnService)
+	purchaseReturnController := controllers.NewPurchaseReturnController(purchaseReturnService)
+	pricingController := controllers.NewPricingController(pricingService)
+	labelController := controllers.NewLabelController(labelService)
+	supplierPriceListController := controllers.NewSupplierPriceListController(supplierPriceListService)
+	productSupplierController := controllers.NewProductSupplierController(productSupplierService)
+	productImageController := controllers.NewProductImageController(productImageService)
+	productBundleController := controllers.NewProductBundleController(productBundleService)
+	schoolListController := controllers.NewSchoolListController(schoolListService)
 
 	router := r.Group("/api/v1")
 
-	budget := router.Group("/budget")
+	// Las imágenes se sirven sin pasar por la auditoría para poder usarlas en <img>
+	images := router.Group("/images")
 	{
-		budget.GET("", budgetController.GetBudget())
+		images.GET("/:id", productImageController.Serve(false))
+		images.GET("/:id/thumbnail", productImageController.Serve(true))
 	}
 
 	health := router.Group("/healthy")
@@ -67,11 +142,16 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 		categories := private.Group("/categories")
 		{
 			categories.GET("", common.Get(categoryOps))
+			categories.GET("/tree", categoryController.GetTree())
 			categories.GET("/:id", common.GetByID(categoryOps))
 			categories.POST("", common.Create[models.Category, requests.CategoryRequest](categoryOps))
 			categories.POST("/list", common.CreateMany[models.Category, requests.CategoryRequestArray](categoryOps))
 			categories.PUT("/:id", common.Update[models.Category, requests.CategoryRequest](categoryOps))
+			categories.PATCH("/:id", common.Patch[models.Category, requests.CategoryRequest](categoryOps))
+			categories.PUT("/:id/move", categoryController.Move())
+			categories.POST("/:id/reprice", categoryController.Reprice())
 			categories.DELETE("/:id", common.Delete(categoryOps))
+			categories.POST("/:id/merge", common.Merge(categoryOps))
 		}
 		brands := private.Group("/brands")
 		{
@@ -80,7 +160,9 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			brands.POST("", common.Create[models.Brand, requests.BrandRequest](brandOps))
 			brands.POST("/list", common.CreateMany[models.Brand, requests.BrandRequestArray](brandOps))
 			brands.PUT("/:id", common.Update[models.Brand, requests.BrandRequest](brandOps))
+			brands.PATCH("/:id", common.Patch[models.Brand, requests.BrandRequest](brandOps))
 			brands.DELETE("/:id", common.Delete(brandOps))
+			brands.POST("/:id/merge", common.Merge(brandOps))
 		}
 		customers := private.Group("/customers")
 		{
@@ -88,7 +170,10 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			customers.GET("/:id", common.GetByID(customerdOps))
 			customers.POST("", common.Create[models.Customer, requests.CustomerRequest](customerdOps))
 			customers.PUT("/:id", common.Update[models.Customer, requests.CustomerRequest](customerdOps))
+			customers.PATCH("/:id", common.Patch[models.Customer, requests.CustomerRequest](customerdOps))
 			customers.DELETE("/:id", common.Delete(customerdOps))
+			customers.POST("/:id/merge", common.Merge(customerdOps))
+			customers.GET("/:id/account", sellReturnController.GetCustomerAccount())
 		}
 		suppliers := private.Group("/suppliers")
 		{
@@ -96,16 +181,54 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			suppliers.GET("/:id", common.GetByID(supplierOps))
 			suppliers.POST("", common.Create[models.Supplier, requests.SupplierRequest](supplierOps))
 			suppliers.PUT("/:id", common.Update[models.Supplier, requests.SupplierRequest](supplierOps))
+			suppliers.PATCH("/:id", common.Patch[models.Supplier, requests.SupplierRequest](supplierOps))
 			suppliers.DELETE("/:id", common.Delete(supplierOps))
+			suppliers.POST("/:id/merge", common.Merge(supplierOps))
+			suppliers.GET("/:id/account", purchaseReturnController.GetSupplierAccount())
+			suppliers.GET("/:id/products", productSupplierController.GetCatalog())
+			suppliers.GET("/:id/price-list-mapping", supplierPriceListController.GetMapping())
+			suppliers.PUT("/:id/price-list-mapping", supplierPriceListController.SaveMapping())
+			suppliers.GET("/:id/price-lists", supplierPriceListController.GetBatches())
+			suppliers.POST("/:id/price-lists", supplierPriceListController.Upload())
+		}
+		productSuppliers := private.Group("/product-suppliers")
+		{
+			productSuppliers.POST("", productSupplierController.Create())
+			productSuppliers.PUT("/:id", productSupplierController.Update())
+			productSuppliers.DELETE("/:id", productSupplierController.Delete())
+		}
+		supplierPriceLists := private.Group("/supplier-price-lists")
+		{
+			supplierPriceLists.GET("/:id", supplierPriceListController.GetBatch())
+			supplierPriceLists.POST("/:id/apply", supplierPriceListController.Apply())
+			supplierPriceLists.POST("/:id/discard", supplierPriceListController.Discard())
 		}
 		products := private.Group("/products")
 		{
 			products.GET("/:id", common.GetByID(productOps))
 			products.GET("", productController.FindAllWithCategoriesAndBrands())
 			products.GET("/export", productController.GetExport())
+			products.GET("/search", productController.Search())
+			products.GET("/by-barcode/:code", productController.FindByBarcode())
+			products.GET("/reorder-suggestions", productSupplierController.ReorderSuggestions())
+			products.GET("/:id/suppliers", productSupplierController.GetByProduct())
+			products.GET("/:id/barcodes", productController.GetBarcodes())
+			products.POST("/:id/barcodes", productController.AddBarcode())
+			products.DELETE("/:id/barcodes/:barcodeId", productController.RemoveBarcode())
+			products.GET("/:id/variants", productController.GetVariants())
+			products.POST("/:id/variants", productController.CreateVariant())
+			products.PUT("/:id/variants/:variantId", productController.UpdateVariant())
+			products.GET("/:id/bundle", productBundleController.GetByProduct())
+			products.PUT("/:id/bundle", productBundleController.Save())
+			products.DELETE("/:id/bundle", productBundleController.Delete())
+			products.GET("/:id/images", productImageController.GetByProduct())
+			products.POST("/:id/images", productImageController.Upload())
+			products.DELETE("/:id/images/:imageId", productImageController.Delete())
 			products.POST("", common.Create[models.Product, requests.ProductRequest](productOps))
-			products.POST("/import", productController.ImportFromExcel())
+			products.POST("/import", productController.Import())
+			products.POST("/labels", labelController.GetLabels())
 			products.PUT("/:id", common.Update[models.Product, requests.ProductRequest](productOps))
+			products.PATCH("/:id", common.Patch[models.Product, requests.ProductRequest](productOps))
 			products.DELETE("/:id", common.Delete(productOps))
 		}
 		purchaseHistories := private.Group("/purchases")
@@ -114,7 +237,12 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			purchaseHistories.GET("", common.Get(ops))
 			purchaseHistories.GET("/:id", common.GetByID(ops))
 			purchaseHistories.POST("", purchaseController.CreatePurchaseHistory())
-			purchaseHistories.DELETE("/:id", purchaseController.DeletePurchaseHistory())
+		}
+		purchaseReturns := private.Group("/purchase-returns")
+		{
+			purchaseReturns.GET("", purchaseReturnController.GetAll())
+			purchaseReturns.GET("/:id", purchaseReturnController.GetByID())
+			purchaseReturns.POST("", purchaseReturnController.CreatePurchaseReturn())
 		}
 		sellHistories := private.Group("/sells")
 		{
@@ -122,7 +250,13 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			sellHistories.GET("", common.Get(ops))
 			sellHistories.GET("/:id", common.GetByID(ops))
 			sellHistories.POST("", sellController.CreateSellHistory())
-			sellHistories.DELETE("/:id", sellController.DeleteSellHistory())
+			sellHistories.POST("/list", sellController.CreateSellHistories())
+		}
+		sellReturns := private.Group("/sell-returns")
+		{
+			sellReturns.GET("", sellReturnController.GetAll())
+			sellReturns.GET("/:id", sellReturnController.GetByID())
+			sellReturns.POST("", sellReturnController.CreateSellReturn())
 		}
 		stocks := private.Group("/stocks")
 		{
@@ -137,6 +271,17 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 			priceLists.GET("/:id", common.GetByID(ops))
 			priceLists.POST("", common.Create[models.PriceList, requests.PriceListRequest](ops))
 		}
+		promotions := private.Group("/promotions")
+		{
+			ops := common.NewGormOperations[models.Promotion](app.DB)
+			promotions.GET("", common.Get(ops))
+			promotions.GET("/:id", common.GetByID(ops))
+			promotions.POST("", common.Create[models.Promotion, requests.PromotionRequest](ops))
+			promotions.POST("/evaluate", pricingController.Evaluate())
+			promotions.PUT("/:id", common.Update[models.Promotion, requests.PromotionRequest](ops))
+			promotions.PATCH("/:id", common.Patch[models.Promotion, requests.PromotionRequest](ops))
+			promotions.DELETE("/:id", common.Delete(ops))
+		}
 		dashboard := private.Group("/dashboard")
 		{
 			dashboard.GET("", dashboardController.GetData())
@@ -145,9 +290,41 @@ func SetupRoutes(r *gin.Engine, app *app.App) {
 		budges := private.Group("/budges")
 		{
 			ops := common.NewGormOperations[models.Budget](app.DB)
-			budges.POST("", common.Create[models.Budget, requests.BudgetRequest](ops))
+			budges.GET("", common.Get(ops))
+			budges.GET("/:id", common.GetByID(ops))
+			budges.GET("/:id/pdf", budgetController.GetPDF())
+			budges.POST("", budgetController.CreateBudget())
 			budges.DELETE("/:id", common.Delete(ops))
 		}
 
+		schoolLists := private.Group("/school-lists")
+		{
+			ops := common.NewGormOperations[models.SchoolList](app.DB)
+			schoolLists.GET("", common.Get(ops))
+			schoolLists.GET("/:id", schoolListController.GetByID())
+			schoolLists.POST("", schoolListController.Create())
+			schoolLists.PUT("/:id", schoolListController.Update())
+			schoolLists.PUT("/:id/lines/:lineId", schoolListController.MapLine())
+			schoolLists.POST("/:id/generate", schoolListController.Generate())
+			schoolLists.DELETE("/:id", common.Delete(ops))
+		}
+
+		jobs := private.Group("/jobs")
+		{
+			jobs.GET("/:id", importJobController.GetByID())
+		}
+
+		// Papelera: registros borrados, con restauración y purga pasada la retención
+		trashGroup := private.Group("/trash")
+		{
+			common.TrashRoutes(trash, trashGroup, "categories", categoryOps)
+			common.TrashRoutes(trash, trashGroup, "brands", brandOps)
+			common.TrashRoutes(trash, trashGroup, "customers", customerdOps)
+			common.TrashRoutes(trash, trashGroup, "suppliers", supplierOps)
+			common.TrashRoutes(trash, trashGroup, "products", productOps)
+			trashGroup.DELETE("", trash.PurgeHandler())
+		}
+		trash.Start()
+
 	}
 }
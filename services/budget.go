@@ -1,7 +1,11 @@
 package services
 
 import (
-	"log"
+	"fmt"
+	"libreria/models"
+	"libreria/requests"
+	"libreria/utils"
+	"strconv"
 
 	"github.com/johnfercher/maroto/v2"
 	"gorm.io/gorm"
@@ -11,6 +15,7 @@ import (
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 
 	"github.com/johnfercher/maroto/v2/pkg/config"
@@ -19,20 +24,75 @@ import (
 )
 
 type BudgetService interface {
-	GeneratePDF() ([]byte, error)
+	CreateBudget(request requests.BudgetRequest) (models.Budget, error)
+	GeneratePDF(id string, withImages bool) ([]byte, error)
 }
 
 type budgetService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	pricingService PricingService
+	imageService   ProductImageService
 }
 
-func NewBudgetService(db *gorm.DB) BudgetService {
+func NewBudgetService(db *gorm.DB, pricingService PricingService, imageService ProductImageService) BudgetService {
 	return &budgetService{
-		db: db,
+		db:             db,
+		pricingService: pricingService,
+		imageService:   imageService,
 	}
 }
 
-func (s *budgetService) GeneratePDF() ([]byte, error) {
+func (s *budgetService) CreateBudget(request requests.BudgetRequest) (models.Budget, error) {
+	budget, err := request.ToModel()
+	if err != nil {
+		return models.Budget{}, err
+	}
+
+	pricing, err := s.pricingService.PriceLines(request.Items)
+	if err != nil {
+		return models.Budget{}, err
+	}
+
+	for _, line := range pricing.Lines {
+		budget.Items = append(budget.Items, models.BudgetItem{
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Discount:    line.Discount,
+			Subtotal:    line.Total,
+		})
+	}
+	budget.Discount = pricing.Discount
+	budget.Total = pricing.Total
+
+	if err := s.db.Create(&budget).Error; err != nil {
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// budgetValidDays es la vigencia de un presupuesto desde su emisión
+const budgetValidDays = 7
+
+func (s *budgetService) GeneratePDF(id string, withImages bool) ([]byte, error) {
+	var budget models.Budget
+	if err := s.db.First(&budget, id).Error; err != nil {
+		return nil, fmt.Errorf("presupuesto con ID %s no encontrado", id)
+	}
+
+	var thumbnails map[uint][]byte
+	if withImages {
+		productIDs := make([]uint, 0, len(budget.Items))
+		for _, item := range budget.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		var err error
+		thumbnails, err = s.imageService.Thumbnails(productIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	cfg := config.NewBuilder().
 		WithPageNumber().
@@ -46,14 +106,12 @@ func (s *budgetService) GeneratePDF() ([]byte, error) {
 	mrt := maroto.New(cfg)
 	m := maroto.NewMetricsDecorator(mrt)
 
-	err := m.RegisterHeader(getPageHeader())
-	if err != nil {
-		log.Fatal(err.Error())
+	if err := m.RegisterHeader(getPageHeader()); err != nil {
+		return nil, err
 	}
 
-	err = m.RegisterFooter(getPageFooter())
-	if err != nil {
-		log.Fatal(err.Error())
+	if err := m.RegisterFooter(getPageFooter()); err != nil {
+		return nil, err
 	}
 
 	m.AddRows(text.NewRow(10, "Presupuesto", props.Text{
@@ -62,10 +120,10 @@ func (s *budgetService) GeneratePDF() ([]byte, error) {
 		Align: align.Center,
 		Size:  14,
 	}),
-		text.NewRow(10, "Cliente: Fito", props.Text{Align: align.Left}),
-		text.NewRow(10, "Descripción: Útiles para primero de Pompeya", props.Text{Align: align.Left}),
-		text.NewRow(10, "Fecha: 07/08/2025", props.Text{Align: align.Left}),
-		text.NewRow(10, "Vence: 14/08/2025", props.Text{Align: align.Left}),
+		text.NewRow(10, "Cliente: "+budget.ClientName, props.Text{Align: align.Left}),
+		text.NewRow(10, "Descripción: "+budget.Description, props.Text{Align: align.Left}),
+		text.NewRow(10, "Fecha: "+budget.CreatedAt.Format("02/01/2006"), props.Text{Align: align.Left}),
+		text.NewRow(10, "Vence: "+budget.CreatedAt.AddDate(0, 0, budgetValidDays).Format("02/01/2006"), props.Text{Align: align.Left}),
 	)
 
 	m.AddRow(7,
@@ -78,44 +136,73 @@ func (s *budgetService) GeneratePDF() ([]byte, error) {
 		}),
 	).WithStyle(&props.Cell{BackgroundColor: darkGrayColor})
 
-	m.AddRows(getItems()...)
+	m.AddRows(getItems(budget, withImages, thumbnails)...)
 
 	document, err := m.Generate()
 	if err != nil {
-		log.Fatal(err.Error())
+		return nil, err
 	}
 	return document.GetBytes(), nil
 }
 
-func getItems() []core.Row {
-	rows := []core.Row{
-		row.New(4).Add(
-			text.NewCol(5, "Producto", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
-			text.NewCol(2, "Cantidad", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
-			text.NewCol(3, "Precio unitario", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
-			text.NewCol(2, "Subtotal", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
-		),
+// getItems arma la tabla de productos. Con imágenes, la primera columna
+// muestra la miniatura de cada producto y se achica la del nombre.
+func getItems(budget models.Budget, withImages bool, thumbnails map[uint][]byte) []core.Row {
+	nameSize, height := 5, 4.0
+	if withImages {
+		nameSize, height = 4, 12
 	}
 
-	var contentsRow []core.Row
-	contents := getContents()
+	header := row.New(4)
+	if withImages {
+		header.Add(col.New(1))
+	}
+	header.Add(
+		text.NewCol(nameSize, "Producto", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
+		text.NewCol(2, "Cantidad", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
+		text.NewCol(3, "Precio unitario", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
+		text.NewCol(2, "Subtotal", props.Text{Size: 9, Align: align.Center, Style: fontstyle.Bold}),
+	)
+	rows := []core.Row{header}
 
-	for i, content := range contents {
-		r := row.New(4).Add(
-			text.NewCol(5, content[0], props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(2, content[1], props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(3, content[2], props.Text{Size: 8, Align: align.Center}),
-			text.NewCol(2, content[3], props.Text{Size: 8, Align: align.Center}),
+	for i, item := range budget.Items {
+		r := row.New(height)
+		if withImages {
+			if thumbnail, ok := thumbnails[item.ProductID]; ok {
+				r.Add(image.NewFromBytesCol(1, thumbnail, extension.Jpg, props.Rect{Center: true, Percent: 90}))
+			} else {
+				r.Add(col.New(1))
+			}
+		}
+		r.Add(
+			text.NewCol(nameSize, item.ProductName, props.Text{Size: 8, Align: align.Center}),
+			text.NewCol(2, strconv.Itoa(item.Quantity), props.Text{Size: 8, Align: align.Center}),
+			text.NewCol(3, utils.FormatMoney(item.UnitPrice), props.Text{Size: 8, Align: align.Center}),
+			text.NewCol(2, utils.FormatMoney(item.Subtotal), props.Text{Size: 8, Align: align.Center}),
 		)
 		if i%2 == 0 {
 			gray := getGrayColor()
 			r.WithStyle(&props.Cell{BackgroundColor: gray})
 		}
 
-		contentsRow = append(contentsRow, r)
+		rows = append(rows, r)
 	}
 
-	rows = append(rows, contentsRow...)
+	if budget.Discount > 0 {
+		rows = append(rows, row.New(10).Add(
+			col.New(6),
+			text.NewCol(2, "Descuento:", props.Text{
+				Top:   5,
+				Size:  8,
+				Align: align.Right,
+			}),
+			text.NewCol(3, "-"+utils.FormatMoney(budget.Discount), props.Text{
+				Top:   5,
+				Size:  8,
+				Align: align.Center,
+			}),
+		))
+	}
 
 	rows = append(rows, row.New(20).Add(
 		col.New(6),
@@ -125,7 +212,7 @@ func getItems() []core.Row {
 			Size:  8,
 			Align: align.Right,
 		}),
-		text.NewCol(3, "$ 22.800,00", props.Text{
+		text.NewCol(3, utils.FormatMoney(budget.Total), props.Text{
 			Top:   5,
 			Style: fontstyle.Bold,
 			Size:  8,
@@ -219,13 +306,3 @@ func getPrimaryColor() *props.Color {
 		Blue:  167,
 	}
 }
-
-func getContents() [][]string {
-	return [][]string{
-		{"Lapicera Bic", "2", "$ 800,00", "$ 1.600,00"},
-		{"Hojas Rivadavia A4", "3", "$ 1.700,00", "$ 5.100,00"},
-		{"Tijera Mapped", "1", "$ 1200,00", "$ 1.200,00"},
-		{"Goma Mapped", "4", "$ 350,00", "$ 1.400,00"},
-		{"Cuaderno Rivadavia", "3", "$ 4.500,00", "$ 13.500,00"},
-	}
-}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/utils"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxProfitMargin es el mayor margen que entra en la columna decimal(5,2).
+const maxProfitMargin = 999.99
+
+type CategoryService interface {
+	Tree() ([]models.Category, error)
+	Move(id uint, request requests.MoveCategoryRequest) (models.Category, error)
+	Reprice(id uint, request requests.CategoryRepriceRequest) (int, error)
+}
+
+type categoryService struct {
+	db             *gorm.DB
+	categoryRepo   repositories.CategoryRepository
+	pricingService PricingService
+}
+
+func NewCategoryService(db *gorm.DB, categoryRepo repositories.CategoryRepository, pricingService PricingService) CategoryService {
+	return &categoryService{
+		db:             db,
+		categoryRepo:   categoryRepo,
+		pricingService: pricingService,
+	}
+}
+
+// Tree devuelve las categorías de primer nivel con sus subcategorías anidadas.
+func (s *categoryService) Tree() ([]models.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[uint][]models.Category{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var build func(category models.Category) models.Category
+	build = func(category models.Category) models.Category {
+		for _, child := range children[category.ID] {
+			category.Children = append(category.Children, build(child))
+		}
+		return category
+	}
+
+	roots := []models.Category{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, build(category))
+		}
+	}
+	return roots, nil
+}
+
+func (s *categoryService) Move(id uint, request requests.MoveCategoryRequest) (models.Category, error) {
+	category, err := s.categoryRepo.FindByID(id)
+	if err != nil {
+		return models.Category{}, fmt.Errorf("categoría con ID %d no encontrada", id)
+	}
+
+	if err := request.Validate(s.db, id); err != nil {
+		return models.Category{}, err
+	}
+	if err := requests.ValidateCategoryName(s.db, category.Name, request.ParentID, id); err != nil {
+		return models.Category{}, err
+	}
+
+	if err := s.categoryRepo.Move(id, request.ParentID); err != nil {
+		return models.Category{}, err
+	}
+	return s.categoryRepo.FindByID(id)
+}
+
+// Reprice aplica el porcentaje al precio vigente de todos los productos de la
+// categoría y sus subcategorías. Las variantes con precio propio se ajustan
+// en ese precio; en el resto se ajusta el margen, que es de donde sale el
+// precio, y el nuevo precio queda en la lista de precios como registro del
+// cambio (las etiquetas lo usan para saber qué se remarcó).
+func (s *categoryService) Reprice(id uint, request requests.CategoryRepriceRequest) (int, error) {
+	if _, err := s.categoryRepo.FindByID(id); err != nil {
+		return 0, fmt.Errorf("categoría con ID %d no encontrada", id)
+	}
+
+	products, err := s.categoryRepo.FindForReprice(id)
+	if err != nil {
+		return 0, err
+	}
+
+	factor := 1 + request.Percentage/100
+	now := time.Now()
+	updated := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, product := range products {
+			if product.PriceOverride != nil {
+				price := utils.RoundMoney(*product.PriceOverride * factor)
+				if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).
+					Updates(map[string]interface{}{"price_override": price, "version": models.NextVersion()}).Error; err != nil {
+					return err
+				}
+				updated++
+				continue
+			}
+
+			price, err := s.pricingService.UnitPrice(product)
+			if err != nil {
+				return err
+			}
+			if price <= 0 {
+				continue // sin costo ni precio no hay nada que ajustar
+			}
+
+			// costo * (1 + margen') = costo * (1 + margen) * factor
+			margin := math.Round(((1+product.ProfitMargin/100)*factor-1)*100*100) / 100
+			if margin <= 0 || margin > maxProfitMargin {
+				return fmt.Errorf("el margen de '%s' quedaría en %.2f%%, fuera del rango permitido", product.Name, margin)
+			}
+			if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).
+				Updates(map[string]interface{}{"profit_margin": margin, "version": models.NextVersion()}).Error; err != nil {
+				return fmt.Errorf("error al actualizar el precio de '%s': %v", product.Name, err)
+			}
+
+			priceList := models.PriceList{
+				ProductID:   product.ID,
+				Price:       utils.RoundMoney(price * factor),
+				EffectiveAt: now,
+				IsActive:    true,
+			}
+			if err := tx.Create(&priceList).Error; err != nil {
+				return fmt.Errorf("error al actualizar el precio de '%s': %v", product.Name, err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
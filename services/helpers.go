@@ -3,6 +3,9 @@ package services
 import (
 	"libreria/constants"
 	"libreria/models"
+	"libreria/repositories"
+
+	"gorm.io/gorm"
 )
 
 func applyMovementFlow(productStockService ProductStockService, stockMovementService StockMovementService, productID uint, qty int, movType constants.StockMovementType, refID uint, note string) error {
@@ -25,3 +28,12 @@ func applyMovementFlow(productStockService ProductStockService, stockMovementSer
 
 	return nil
 }
+
+// applyMovementFlowTx es igual a applyMovementFlow pero usa servicios ligados a
+// la transacción recibida, de modo que el stock y el movimiento se confirman o
+// se descartan junto con el documento que los origina.
+func applyMovementFlowTx(tx *gorm.DB, productID uint, qty int, movType constants.StockMovementType, refID uint, note string) error {
+	productStockService := NewProductStockService(tx, repositories.NewProductStockRepository(tx))
+	stockMovementService := NewStockMovementService(tx, repositories.NewStockMovementRepository(tx))
+	return applyMovementFlow(productStockService, stockMovementService, productID, qty, movType, refID, note)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportProgress informa cuántas filas se procesaron en cada etapa: al validar,
+// sobre las filas de la planilla; al guardar, sobre los productos a escribir.
+type ImportProgress func(phase constants.ImportJobPhase, processed, total int)
+
+type ImportJobService interface {
+	EnqueueProductImport(fileName string, content []byte, options requests.ProductImportOptions) (models.ImportJob, error)
+	GetByID(id string) (models.ImportJob, error)
+	Start()
+}
+
+type importJobService struct {
+	db             *gorm.DB
+	jobRepo        repositories.ImportJobRepository
+	productService ProductService
+	wake           chan struct{}
+}
+
+// Cada cuántas filas se guarda el avance, para no escribir en la base por cada fila
+const importProgressStep = 100
+
+// Si se pierde un aviso de trabajo nuevo, el worker igual revisa la cola cada tanto
+const importPollInterval = 30 * time.Second
+
+func NewImportJobService(db *gorm.DB, jobRepo repositories.ImportJobRepository, productService ProductService) ImportJobService {
+	return &importJobService{
+		db:             db,
+		jobRepo:        jobRepo,
+		productService: productService,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+func (s *importJobService) EnqueueProductImport(fileName string, content []byte, options requests.ProductImportOptions) (models.ImportJob, error) {
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+
+	job := models.ImportJob{
+		Kind:     string(constants.IMPORT_JOB_KIND_PRODUCTS),
+		Status:   string(constants.IMPORT_JOB_STATUS_PENDING),
+		FileName: fileName,
+		Content:  content,
+		Options:  encodedOptions,
+	}
+	if err := s.jobRepo.Create(&job); err != nil {
+		return models.ImportJob{}, fmt.Errorf("no se pudo encolar la importación: %v", err)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default: // el worker ya tiene un aviso pendiente
+	}
+
+	return job, nil
+}
+
+func (s *importJobService) GetByID(id string) (models.ImportJob, error) {
+	return s.jobRepo.FindByID(id)
+}
+
+// Start lanza el worker que procesa los trabajos de a uno, empezando por los
+// que quedaron pendientes antes de un reinicio.
+func (s *importJobService) Start() {
+	go func() {
+		if err := s.jobRepo.ResetRunning(); err != nil {
+			log.Println("Error al retomar importaciones pendientes:", err)
+		}
+
+		ticker := time.NewTicker(importPollInterval)
+		defer ticker.Stop()
+		for {
+			job, err := s.jobRepo.NextPending()
+			if err == nil {
+				s.run(job)
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Println("Error al buscar importaciones pendientes:", err)
+			}
+
+			select {
+			case <-s.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *importJobService) run(job models.ImportJob) {
+	now := time.Now()
+	job.Status = string(constants.IMPORT_JOB_STATUS_RUNNING)
+	job.StartedAt = &now
+	if err := s.jobRepo.Save(&job); err != nil {
+		log.Printf("Error al iniciar la importación %d: %v", job.ID, err)
+		return
+	}
+
+	report, err := s.process(&job)
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Content = nil // el archivo ya no hace falta
+	if err != nil {
+		job.Status = string(constants.IMPORT_JOB_STATUS_FAILED)
+		job.Error = err.Error()
+	} else {
+		job.Status = string(constants.IMPORT_JOB_STATUS_DONE)
+		job.Report = report
+		job.Processed = job.TotalRows
+	}
+	if err := s.jobRepo.Save(&job); err != nil {
+		log.Printf("Error al guardar el resultado de la importación %d: %v", job.ID, err)
+	}
+}
+
+// process actualiza en job el avance a medida que se procesan las filas, para
+// que el guardado final en run no pise lo que ya se informó.
+func (s *importJobService) process(job *models.ImportJob) (report json.RawMessage, err error) {
+	// Un archivo malformado no debe tirar abajo el worker
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("error inesperado al procesar el archivo: %v", r)
+		}
+	}()
+
+	var options requests.ProductImportOptions
+	if err := json.Unmarshal(job.Options, &options); err != nil {
+		return nil, fmt.Errorf("opciones de importación inválidas: %v", err)
+	}
+
+	progress := func(phase constants.ImportJobPhase, processed, total int) {
+		// El cambio de etapa se guarda siempre, aunque no toque por cantidad de filas
+		changed := job.Phase != string(phase)
+		job.Phase = string(phase)
+		job.Processed = processed
+		job.TotalRows = total
+		if changed || processed%importProgressStep == 0 || processed == total {
+			if err := s.jobRepo.UpdateProgress(job.ID, phase, processed, total); err != nil {
+				log.Printf("Error al guardar el avance de la importación %d: %v", job.ID, err)
+			}
+		}
+	}
+
+	result, err := s.productService.ImportProducts(bytes.NewReader(job.Content), options, progress)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/utils"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/code"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/barcode"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"gorm.io/gorm"
+)
+
+const (
+	a4Width  = 210.0
+	a4Height = 297.0
+)
+
+type labelLayout struct {
+	Columns     int
+	Rows        int
+	MarginTop   float64
+	MarginLeft  float64
+	MarginRight float64
+	LabelHeight float64
+}
+
+// Hojas autoadhesivas A4 más comunes (medidas en mm).
+var labelLayouts = map[string]labelLayout{
+	"a4-2x7":  {Columns: 2, Rows: 7, MarginTop: 15.15, MarginLeft: 4.65, MarginRight: 4.65, LabelHeight: 38.1},
+	"a4-3x7":  {Columns: 3, Rows: 7, MarginTop: 0, MarginLeft: 0, MarginRight: 0, LabelHeight: 42.4},
+	"a4-3x8":  {Columns: 3, Rows: 8, MarginTop: 0.5, MarginLeft: 0, MarginRight: 0, LabelHeight: 37},
+	"a4-4x10": {Columns: 4, Rows: 10, MarginTop: 21.5, MarginLeft: 8, MarginRight: 8, LabelHeight: 25.4},
+	"a4-5x13": {Columns: 5, Rows: 13, MarginTop: 10.7, MarginLeft: 9.75, MarginRight: 9.75, LabelHeight: 21.2},
+}
+
+const defaultLabelLayout = "a4-3x8"
+
+type shelfLabel struct {
+	Name          string
+	Price         string
+	Barcode       string
+	BarcodeType   barcode.Type
+	LastPriceDate string
+}
+
+type LabelService interface {
+	GeneratePDF(request requests.LabelRequest) ([]byte, error)
+}
+
+type labelService struct {
+	db             *gorm.DB
+	productRepo    repositories.ProductRepository
+	pricingService PricingService
+}
+
+func NewLabelService(db *gorm.DB, productRepo repositories.ProductRepository, pricingService PricingService) LabelService {
+	return &labelService{
+		db:             db,
+		productRepo:    productRepo,
+		pricingService: pricingService,
+	}
+}
+
+func (s *labelService) GeneratePDF(request requests.LabelRequest) ([]byte, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	layout, err := resolveLabelLayout(request)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := s.buildLabels(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, errors.New("no hay productos para imprimir")
+	}
+
+	cfg := config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithTopMargin(layout.MarginTop).
+		WithLeftMargin(layout.MarginLeft).
+		WithRightMargin(layout.MarginRight).
+		WithBottomMargin(0).
+		WithMaxGridSize(layout.Columns).
+		Build()
+
+	m := maroto.New(cfg)
+	labelWidth := (a4Width - layout.MarginLeft - layout.MarginRight) / float64(layout.Columns)
+
+	for start := 0; start < len(labels); start += layout.Columns {
+		end := start + layout.Columns
+		if end > len(labels) {
+			end = len(labels)
+		}
+
+		cols := make([]core.Col, 0, layout.Columns)
+		for _, label := range labels[start:end] {
+			cols = append(cols, labelCol(label, labelWidth, layout.LabelHeight, request.Border))
+		}
+		for len(cols) < layout.Columns {
+			cols = append(cols, col.New(1))
+		}
+		m.AddRow(layout.LabelHeight, cols...)
+	}
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return document.GetBytes(), nil
+}
+
+func (s *labelService) buildLabels(request requests.LabelRequest) ([]shelfLabel, error) {
+	products, err := s.productRepo.FindForLabels(request.ProductIDs, request.CategoryID, request.RepricedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	priceChanges, err := s.productRepo.LastPriceChanges(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	copies := request.Copies
+	if copies == 0 {
+		copies = 1
+	}
+
+	labels := make([]shelfLabel, 0, len(products)*copies)
+	for _, product := range products {
+		price, err := s.pricingService.UnitPrice(product)
+		if err != nil {
+			return nil, err
+		}
+
+		lastChange := product.UpdatedAt
+		if changedAt, ok := priceChanges[product.ID]; ok {
+			lastChange = changedAt
+		}
+
+		label := shelfLabel{
+			Name:          product.Name,
+			Price:         utils.FormatMoney(price),
+			LastPriceDate: lastChange.Format("02/01/2006"),
+		}
+		label.Barcode, label.BarcodeType = labelBarcode(product)
+
+		for i := 0; i < copies; i++ {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// labelBarcode usa el primer código de barras del producto y, si no tiene,
+// imprime su código interno como Code128.
+func labelBarcode(product models.Product) (string, barcode.Type) {
+	if len(product.Barcodes) == 0 {
+		return product.Code, barcode.Code128
+	}
+	code := product.Barcodes[0].Code
+	if len(code) == 12 {
+		// UPC-A se imprime como EAN-13 con un 0 adelante
+		code = "0" + code
+	}
+	return code, barcode.EAN
+}
+
+func resolveLabelLayout(request requests.LabelRequest) (labelLayout, error) {
+	name := request.Layout
+	if name == "" {
+		name = defaultLabelLayout
+	}
+	layout, ok := labelLayouts[name]
+	if !ok {
+		return labelLayout{}, fmt.Errorf("formato de etiqueta '%s' desconocido", name)
+	}
+
+	if request.Columns > 0 {
+		layout.Columns = request.Columns
+	}
+	if request.Rows > 0 {
+		layout.Rows = request.Rows
+		layout.LabelHeight = 0
+	}
+	if request.MarginTop != nil {
+		layout.MarginTop = *request.MarginTop
+	}
+	if request.MarginLeft != nil {
+		layout.MarginLeft = *request.MarginLeft
+	}
+	if request.MarginRight != nil {
+		layout.MarginRight = *request.MarginRight
+	}
+	if request.LabelHeight > 0 {
+		layout.LabelHeight = request.LabelHeight
+	}
+	if layout.LabelHeight == 0 {
+		layout.LabelHeight = (a4Height - layout.MarginTop) / float64(layout.Rows)
+	}
+
+	if layout.MarginLeft+layout.MarginRight >= a4Width {
+		return labelLayout{}, errors.New("los márgenes laterales no dejan espacio para las etiquetas")
+	}
+	if layout.MarginTop+layout.LabelHeight*float64(layout.Rows) > a4Height+0.5 {
+		return labelLayout{}, errors.New("las filas de etiquetas no entran en una hoja A4")
+	}
+	return layout, nil
+}
+
+func labelCol(label shelfLabel, width, height float64, withBorder bool) core.Col {
+	nameSize := 7.0
+	priceSize := 14.0
+	if height < 30 {
+		nameSize, priceSize = 6, 10
+	}
+
+	barcodePercent := 55.0
+	c := col.New(1).Add(
+		text.New(label.Name, props.Text{Top: 1.5, Left: 1.5, Right: 1.5, Size: nameSize, Style: fontstyle.Bold, Align: align.Center}),
+		text.New(label.Price, props.Text{Top: height * 0.22, Size: priceSize, Style: fontstyle.Bold, Align: align.Center}),
+		code.NewBar(label.Barcode, props.Barcode{
+			Top:     height * 0.5,
+			Left:    width * (1 - barcodePercent/100) / 2,
+			Percent: barcodePercent,
+			Type:    label.BarcodeType,
+		}),
+		text.New("Precio al "+label.LastPriceDate, props.Text{Top: height - 4, Size: 5, Align: align.Center}),
+	)
+	if withBorder {
+		c.WithStyle(&props.Cell{BorderType: border.Full, BorderColor: getGrayColor()})
+	}
+	return c
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"libreria/utils"
+	"math"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PricingService interface {
+	UnitPrice(product models.Product) (float64, error)
+	UnitPrices(products []models.Product) (map[uint]float64, error)
+	PriceLines(lines []requests.PricingLineRequest) (responses.PricingResponse, error)
+}
+
+type pricingService struct {
+	db *gorm.DB
+}
+
+func NewPricingService(db *gorm.DB) PricingService {
+	return &pricingService{db: db}
+}
+
+// UnitPrice devuelve el precio fijo de la variante, si lo tiene; el del combo,
+// si el producto es un combo; si no, el costo promedio más el margen de ganancia.
+func (s *pricingService) UnitPrice(product models.Product) (float64, error) {
+	if product.PriceOverride != nil {
+		return *product.PriceOverride, nil
+	}
+
+	bundle, err := findBundle(s.db, product.ID)
+	if err != nil {
+		return 0, err
+	}
+	if bundle != nil {
+		return s.bundlePrice(*bundle)
+	}
+
+	averageCost, _, err := utils.CalculateAverageCostAndStock(s.db, product.ID)
+	if err != nil {
+		return 0, err
+	}
+	return utils.RoundMoney(averageCost * (1 + product.ProfitMargin/100)), nil
+}
+
+// bundlePrice devuelve el precio fijo del combo o la suma de los precios de
+// sus componentes con el descuento del combo.
+func (s *pricingService) bundlePrice(bundle models.ProductBundle) (float64, error) {
+	if bundle.Pricing == string(constants.BUNDLE_PRICING_FIXED) {
+		return bundle.FixedPrice, nil
+	}
+
+	var total float64
+	for _, component := range bundle.Components {
+		price, err := s.UnitPrice(component.Product)
+		if err != nil {
+			return 0, err
+		}
+		total += price * float64(component.Quantity)
+	}
+	return utils.RoundMoney(total * (1 - bundle.Discount/100)), nil
+}
+
+// UnitPrices es UnitPrice para muchos productos: lee los combos, el stock y las
+// compras de todos en unas pocas consultas en lugar de varias por producto.
+func (s *pricingService) UnitPrices(products []models.Product) (map[uint]float64, error) {
+	prices := make(map[uint]float64, len(products))
+
+	var pending []models.Product
+	for _, product := range products {
+		if product.PriceOverride != nil {
+			prices[product.ID] = *product.PriceOverride
+			continue
+		}
+		pending = append(pending, product)
+	}
+	if len(pending) == 0 {
+		return prices, nil
+	}
+
+	productIDs := make([]uint, 0, len(pending))
+	for _, product := range pending {
+		productIDs = append(productIDs, product.ID)
+	}
+	bundles, err := findBundles(s.db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// El costo de los componentes de los combos se busca junto con el resto
+	costIDs := make([]uint, 0, len(productIDs))
+	for _, product := range pending {
+		bundle, ok := bundles[product.ID]
+		if !ok {
+			costIDs = append(costIDs, product.ID)
+			continue
+		}
+		for _, component := range bundle.Components {
+			costIDs = append(costIDs, component.ProductID)
+		}
+	}
+	averageCosts, _, err := utils.CalculateAverageCostsAndStocks(s.db, costIDs)
+	if err != nil {
+		return nil, err
+	}
+	costPrice := func(product models.Product) float64 {
+		if product.PriceOverride != nil {
+			return *product.PriceOverride
+		}
+		return utils.RoundMoney(averageCosts[product.ID] * (1 + product.ProfitMargin/100))
+	}
+
+	for _, product := range pending {
+		bundle, ok := bundles[product.ID]
+		if !ok {
+			prices[product.ID] = costPrice(product)
+			continue
+		}
+		if bundle.Pricing == string(constants.BUNDLE_PRICING_FIXED) {
+			prices[product.ID] = bundle.FixedPrice
+			continue
+		}
+		var total float64
+		for _, component := range bundle.Components {
+			total += costPrice(component.Product) * float64(component.Quantity)
+		}
+		prices[product.ID] = utils.RoundMoney(total * (1 - bundle.Discount/100))
+	}
+	return prices, nil
+}
+
+// PriceLines valoriza las líneas y aplica las promociones vigentes: a cada línea
+// la mejor promoción de producto, categoría o marca, y luego la mejor promoción
+// sobre el total del pedido, prorrateada entre las líneas.
+func (s *pricingService) PriceLines(lines []requests.PricingLineRequest) (responses.PricingResponse, error) {
+	promotions, err := s.activePromotions()
+	if err != nil {
+		return responses.PricingResponse{}, err
+	}
+
+	priced := make([]responses.PricedLine, 0, len(lines))
+	for _, line := range lines {
+		var product models.Product
+		if err := s.db.First(&product, line.ProductID).Error; err != nil {
+			return responses.PricingResponse{}, fmt.Errorf("producto con ID %d no encontrado", line.ProductID)
+		}
+
+		unitPrice, err := s.UnitPrice(product)
+		if err != nil {
+			return responses.PricingResponse{}, err
+		}
+
+		// Una promoción sobre una categoría alcanza también a sus subcategorías
+		categoryIDs, err := repositories.NewCategoryRepository(s.db).AncestorIDs(product.CategoryID)
+		if err != nil {
+			return responses.PricingResponse{}, err
+		}
+
+		priced = append(priced, priceLine(product, line.Quantity, unitPrice, promotions, categoryIDs))
+	}
+
+	return priceOrder(priced, promotions), nil
+}
+
+// priceLine valoriza una línea y le aplica la mejor promoción de producto,
+// categoría o marca; categoryIDs es la categoría del producto con sus ancestros.
+func priceLine(product models.Product, quantity int, unitPrice float64, promotions []models.Promotion, categoryIDs []uint) responses.PricedLine {
+	priced := responses.PricedLine{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Quantity:    quantity,
+		UnitPrice:   unitPrice,
+		Subtotal:    utils.RoundMoney(unitPrice * float64(quantity)),
+		Discounts:   []responses.AppliedDiscount{},
+	}
+
+	var best *models.Promotion
+	var bestAmount float64
+	for i := range promotions {
+		if !promotionMatchesProduct(promotions[i], product, categoryIDs) {
+			continue
+		}
+		if amount := lineDiscount(promotions[i], priced); amount > bestAmount {
+			best, bestAmount = &promotions[i], amount
+		}
+	}
+	if best != nil {
+		priced.Discounts = append(priced.Discounts, responses.AppliedDiscount{PromotionID: best.ID, Name: best.Name, Amount: bestAmount})
+		priced.Discount = bestAmount
+	}
+	priced.Total = utils.RoundMoney(priced.Subtotal - priced.Discount)
+	return priced
+}
+
+// priceOrder aplica la promoción sobre el total a las líneas ya valorizadas y
+// suma los totales del pedido.
+func priceOrder(lines []responses.PricedLine, promotions []models.Promotion) responses.PricingResponse {
+	result := responses.PricingResponse{Lines: lines}
+	for _, line := range result.Lines {
+		result.Total += line.Total
+	}
+
+	applyOrderPromotion(promotions, &result)
+
+	for _, line := range result.Lines {
+		result.Subtotal += line.Subtotal
+		result.Discount += line.Discount
+	}
+	result.Subtotal = utils.RoundMoney(result.Subtotal)
+	result.Discount = utils.RoundMoney(result.Discount)
+	result.Total = utils.RoundMoney(result.Subtotal - result.Discount)
+	return result
+}
+
+func (s *pricingService) activePromotions() ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	now := time.Now()
+	err := s.db.Where("is_active = ? AND starts_at <= ? AND ends_at >= ?", true, now, now).Find(&promotions).Error
+	return promotions, err
+}
+
+// applyOrderPromotion reparte el descuento sobre el total en proporción al total
+// de cada línea, para que cada venta guarde la parte que le corresponde.
+func applyOrderPromotion(promotions []models.Promotion, result *responses.PricingResponse) {
+	orderTotal := result.Total
+	if orderTotal <= 0 {
+		return
+	}
+
+	var best *models.Promotion
+	var bestAmount float64
+	for i := range promotions {
+		promotion := promotions[i]
+		if promotion.Scope != string(constants.PROMOTION_SCOPE_ORDER) || orderTotal < promotion.MinOrderTotal {
+			continue
+		}
+		var amount float64
+		switch constants.PromotionType(promotion.Type) {
+		case constants.PROMOTION_TYPE_PERCENTAGE:
+			amount = orderTotal * promotion.Value / 100
+		case constants.PROMOTION_TYPE_FIXED:
+			amount = math.Min(promotion.Value, orderTotal)
+		}
+		if amount = utils.RoundMoney(amount); amount > bestAmount {
+			best, bestAmount = &promotions[i], amount
+		}
+	}
+	if best == nil {
+		return
+	}
+
+	remaining := bestAmount
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		share := utils.RoundMoney(bestAmount * line.Total / orderTotal)
+		if i == len(result.Lines)-1 {
+			share = utils.RoundMoney(remaining)
+		}
+		remaining -= share
+		if share <= 0 {
+			continue
+		}
+		line.Discounts = append(line.Discounts, responses.AppliedDiscount{PromotionID: best.ID, Name: best.Name, Amount: share})
+		line.Discount = utils.RoundMoney(line.Discount + share)
+		line.Total = utils.RoundMoney(line.Subtotal - line.Discount)
+	}
+}
+
+// promotionMatchesProduct indica si la promoción alcanza al producto;
+// categoryIDs es la categoría del producto junto con sus ancestros.
+func promotionMatchesProduct(promotion models.Promotion, product models.Product, categoryIDs []uint) bool {
+	if promotion.ScopeID == nil {
+		return false
+	}
+	switch constants.PromotionScope(promotion.Scope) {
+	case constants.PROMOTION_SCOPE_PRODUCT:
+		// Una promoción sobre el producto padre alcanza a todas sus variantes
+		return *promotion.ScopeID == product.ID || (product.ParentID != nil && *promotion.ScopeID == *product.ParentID)
+	case constants.PROMOTION_SCOPE_CATEGORY:
+		return slices.Contains(categoryIDs, *promotion.ScopeID)
+	case constants.PROMOTION_SCOPE_BRAND:
+		return *promotion.ScopeID == product.BrandID
+	}
+	return false
+}
+
+func lineDiscount(promotion models.Promotion, line responses.PricedLine) float64 {
+	var amount float64
+	switch constants.PromotionType(promotion.Type) {
+	case constants.PROMOTION_TYPE_PERCENTAGE:
+		amount = line.Subtotal * promotion.Value / 100
+	case constants.PROMOTION_TYPE_FIXED:
+		amount = math.Min(promotion.Value, line.UnitPrice) * float64(line.Quantity)
+	case constants.PROMOTION_TYPE_BUY_X_PAY_Y:
+		if promotion.BuyQuantity > 0 {
+			free := (line.Quantity / promotion.BuyQuantity) * (promotion.BuyQuantity - promotion.PayQuantity)
+			amount = float64(free) * line.UnitPrice
+		}
+	}
+	return utils.RoundMoney(amount)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"libreria/constants"
+	"libreria/models"
+	"libreria/responses"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func uintPtr(value uint) *uint {
+	return &value
+}
+
+func testProduct(productID, categoryID, brandID uint, override *float64) models.Product {
+	return models.Product{
+		Model:         gorm.Model{ID: productID},
+		Name:          "Producto",
+		CategoryID:    categoryID,
+		BrandID:       brandID,
+		PriceOverride: override,
+	}
+}
+
+// El servicio no tiene base: si UnitPrice buscara el combo o el costo fallaría
+func TestUnitPriceUsesOverrideBeforeBundleAndCost(t *testing.T) {
+	s := &pricingService{}
+
+	got, err := s.UnitPrice(testProduct(1, 1, 1, floatPtr(123.45)))
+	if err != nil {
+		t.Fatalf("UnitPrice: %v", err)
+	}
+	if got != 123.45 {
+		t.Errorf("UnitPrice = %v, se esperaba el precio fijo 123.45", got)
+	}
+
+	prices, err := s.UnitPrices([]models.Product{testProduct(1, 1, 1, floatPtr(10)), testProduct(2, 1, 1, floatPtr(20))})
+	if err != nil {
+		t.Fatalf("UnitPrices: %v", err)
+	}
+	if prices[1] != 10 || prices[2] != 20 {
+		t.Errorf("UnitPrices = %v, se esperaban los precios fijos", prices)
+	}
+}
+
+func TestBundlePrice(t *testing.T) {
+	s := &pricingService{}
+
+	fixed := models.ProductBundle{Pricing: string(constants.BUNDLE_PRICING_FIXED), FixedPrice: 99.9}
+	if got, err := s.bundlePrice(fixed); err != nil || got != 99.9 {
+		t.Errorf("combo de precio fijo = %v, %v; se esperaba 99.9", got, err)
+	}
+
+	// (100 x 2 + 50) - 10%
+	components := models.ProductBundle{
+		Pricing:  string(constants.BUNDLE_PRICING_COMPONENTS),
+		Discount: 10,
+		Components: []models.BundleComponent{
+			{ProductID: 2, Product: testProduct(2, 1, 1, floatPtr(100)), Quantity: 2},
+			{ProductID: 3, Product: testProduct(3, 1, 1, floatPtr(50)), Quantity: 1},
+		},
+	}
+	if got, err := s.bundlePrice(components); err != nil || got != 225 {
+		t.Errorf("combo por componentes = %v, %v; se esperaba 225", got, err)
+	}
+}
+
+func TestPriceLinesAppliesLineThenOrderPromotions(t *testing.T) {
+	s := &pricingService{}
+
+	// El precio del combo es la base sobre la que se aplican las promociones
+	bundle := models.ProductBundle{
+		Pricing: string(constants.BUNDLE_PRICING_COMPONENTS),
+		Components: []models.BundleComponent{
+			{ProductID: 10, Product: testProduct(10, 1, 1, floatPtr(60)), Quantity: 1},
+			{ProductID: 11, Product: testProduct(11, 1, 1, floatPtr(40)), Quantity: 1},
+		},
+	}
+	bundlePrice, err := s.bundlePrice(bundle)
+	if err != nil {
+		t.Fatalf("bundlePrice: %v", err)
+	}
+
+	promotions := []models.Promotion{
+		{Model: gorm.Model{ID: 1}, Name: "10% producto", Type: string(constants.PROMOTION_TYPE_PERCENTAGE), Scope: string(constants.PROMOTION_SCOPE_PRODUCT), ScopeID: uintPtr(1), Value: 10},
+		{Model: gorm.Model{ID: 2}, Name: "3x2", Type: string(constants.PROMOTION_TYPE_BUY_X_PAY_Y), Scope: string(constants.PROMOTION_SCOPE_PRODUCT), ScopeID: uintPtr(1), BuyQuantity: 3, PayQuantity: 2},
+		{Model: gorm.Model{ID: 3}, Name: "20% categoría padre", Type: string(constants.PROMOTION_TYPE_PERCENTAGE), Scope: string(constants.PROMOTION_SCOPE_CATEGORY), ScopeID: uintPtr(5), Value: 20},
+		{Model: gorm.Model{ID: 4}, Name: "10% pedido", Type: string(constants.PROMOTION_TYPE_PERCENTAGE), Scope: string(constants.PROMOTION_SCOPE_ORDER), Value: 10, MinOrderTotal: 250},
+		{Model: gorm.Model{ID: 5}, Name: "$20 pedido", Type: string(constants.PROMOTION_TYPE_FIXED), Scope: string(constants.PROMOTION_SCOPE_ORDER), Value: 20},
+		{Model: gorm.Model{ID: 6}, Name: "$100 pedido grande", Type: string(constants.PROMOTION_TYPE_FIXED), Scope: string(constants.PROMOTION_SCOPE_ORDER), Value: 100, MinOrderTotal: 1000},
+	}
+
+	lines := []responses.PricedLine{
+		// 3 x 100: el 3x2 (100) le gana al 10% (30)
+		priceLine(testProduct(1, 7, 2, nil), 3, bundlePrice, promotions, []uint{7}),
+		// 2 x 50 en una subcategoría de la 5: 20% = 20
+		priceLine(testProduct(2, 8, 2, floatPtr(50)), 2, 50, promotions, []uint{8, 5}),
+	}
+	result := priceOrder(lines, promotions)
+
+	// Sobre 280 el 10% (28) le gana a los $20 y no llega al mínimo de $1000.
+	// Se reparte según el total de cada línea: 200/280 y el resto a la última.
+	expected := []struct {
+		subtotal, discount, total float64
+		promotions                []uint
+	}{
+		{300, 120, 180, []uint{2, 4}},
+		{100, 28, 72, []uint{3, 4}},
+	}
+	for i, want := range expected {
+		line := result.Lines[i]
+		if line.Subtotal != want.subtotal || line.Discount != want.discount || line.Total != want.total {
+			t.Errorf("línea %d = subtotal %v, descuento %v, total %v; se esperaba %v, %v, %v",
+				i, line.Subtotal, line.Discount, line.Total, want.subtotal, want.discount, want.total)
+		}
+		if len(line.Discounts) != len(want.promotions) {
+			t.Fatalf("línea %d: descuentos %+v, se esperaban las promociones %v", i, line.Discounts, want.promotions)
+		}
+		for j, promotionID := range want.promotions {
+			if line.Discounts[j].PromotionID != promotionID {
+				t.Errorf("línea %d: descuento %d de la promoción %d, se esperaba %d", i, j, line.Discounts[j].PromotionID, promotionID)
+			}
+		}
+	}
+	if result.Subtotal != 400 || result.Discount != 148 || result.Total != 252 {
+		t.Errorf("pedido = subtotal %v, descuento %v, total %v; se esperaba 400, 148, 252", result.Subtotal, result.Discount, result.Total)
+	}
+}
+
+func TestOrderPromotionRemainderGoesToLastLine(t *testing.T) {
+	promotions := []models.Promotion{
+		{Model: gorm.Model{ID: 1}, Type: string(constants.PROMOTION_TYPE_FIXED), Scope: string(constants.PROMOTION_SCOPE_ORDER), Value: 10},
+	}
+	lines := []responses.PricedLine{
+		priceLine(testProduct(1, 1, 1, nil), 1, 10, nil, nil),
+		priceLine(testProduct(2, 1, 1, nil), 1, 10, nil, nil),
+		priceLine(testProduct(3, 1, 1, nil), 1, 10, nil, nil),
+	}
+
+	result := priceOrder(lines, promotions)
+
+	for i, want := range []float64{3.33, 3.33, 3.34} {
+		if got := result.Lines[i].Discount; got != want {
+			t.Errorf("línea %d: descuento %v, se esperaba %v", i, got, want)
+		}
+	}
+	if result.Discount != 10 || result.Total != 20 {
+		t.Errorf("pedido = descuento %v, total %v; se esperaba 10, 20", result.Discount, result.Total)
+	}
+}
+
+func TestPromotionMatchesVariantThroughParent(t *testing.T) {
+	promotion := models.Promotion{Scope: string(constants.PROMOTION_SCOPE_PRODUCT), ScopeID: uintPtr(1)}
+
+	variant := testProduct(2, 1, 1, nil)
+	variant.ParentID = uintPtr(1)
+	if !promotionMatchesProduct(promotion, variant, nil) {
+		t.Error("la promoción del producto padre tiene que alcanzar a sus variantes")
+	}
+	if promotionMatchesProduct(promotion, testProduct(3, 1, 1, nil), nil) {
+		t.Error("la promoción no tiene que alcanzar a otro producto")
+	}
+}
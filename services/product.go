@@ -6,9 +6,12 @@ import (
 	"libreria/common"
 	"libreria/models"
 	"libreria/repositories"
+	"libreria/requests"
 	"libreria/responses"
+	"libreria/utils"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
@@ -16,26 +19,42 @@ import (
 
 type ProductService interface {
 	GetAllProductsWithCategoriesAndBrands() ([]responses.ProductResponse, error)
-	ExportToExcel() (*excelize.File, error)
-	ImportFromExcel(reader io.Reader) error
+	ExportToExcel(writer io.Writer, options requests.ProductExportOptions) error
+	ExportToCSV(writer io.Writer, options requests.ProductExportOptions) error
+	ImportProducts(reader io.Reader, options requests.ProductImportOptions, progress ImportProgress) (responses.ProductImportReport, error)
+	AnnotateImportErrors(reader io.Reader, options requests.ProductImportOptions) (*excelize.File, error)
+	FindByBarcode(code string) (responses.ProductLookupResponse, error)
+	GetBarcodes(productID uint) ([]models.ProductBarcode, error)
+	AddBarcode(productID uint, request requests.ProductBarcodeRequest) (models.ProductBarcode, error)
+	RemoveBarcode(productID uint, barcodeID string) error
+	Search(options requests.ProductSearchOptions) ([]responses.ProductSearchResult, error)
+	GetVariants(parentID uint) ([]models.Product, error)
+	CreateVariant(parentID uint, request requests.ProductVariantRequest) (models.Product, error)
+	UpdateVariant(parentID uint, variantID string, request requests.ProductVariantRequest) (models.Product, error)
 }
 
 type productService struct {
-	db          *gorm.DB
-	productRepo repositories.ProductRepository
-	categoryOps *common.GormOperations[models.Category]
-	brandOps    *common.GormOperations[models.Brand]
+	db             *gorm.DB
+	productRepo    repositories.ProductRepository
+	categoryOps    *common.GormOperations[models.Category]
+	brandOps       *common.GormOperations[models.Brand]
+	supplierOps    *common.GormOperations[models.Supplier]
+	pricingService PricingService
 }
 
-func NewProductService(db *gorm.DB, productRepo repositories.ProductRepository, categoryOps *common.GormOperations[models.Category], brandOps *common.GormOperations[models.Brand]) ProductService {
+func NewProductService(db *gorm.DB, productRepo repositories.ProductRepository, categoryOps *common.GormOperations[models.Category], brandOps *common.GormOperations[models.Brand], supplierOps *common.GormOperations[models.Supplier], pricingService PricingService) ProductService {
 	return &productService{
-		db:          db,
-		productRepo: productRepo,
-		categoryOps: categoryOps,
-		brandOps:    brandOps,
+		db:             db,
+		productRepo:    productRepo,
+		categoryOps:    categoryOps,
+		brandOps:       brandOps,
+		supplierOps:    supplierOps,
+		pricingService: pricingService,
 	}
 }
 
+// GetAllProductsWithCategoriesAndBrands lista los productos con sus variantes
+// agrupadas dentro del producto padre.
 func (s *productService) GetAllProductsWithCategoriesAndBrands() ([]responses.ProductResponse, error) {
 	products, err := s.productRepo.FindAll()
 
@@ -43,49 +62,158 @@ func (s *productService) GetAllProductsWithCategoriesAndBrands() ([]responses.Pr
 		return []responses.ProductResponse{}, err
 	}
 
+	variants := map[uint][]responses.ProductResponse{}
+	parents := map[uint]bool{}
+	for _, product := range products {
+		if product.ParentID == nil {
+			parents[product.ID] = true
+		} else {
+			variants[*product.ParentID] = append(variants[*product.ParentID], product)
+		}
+	}
+
+	// Las variantes cuyo padre fue eliminado se listan sueltas
+	grouped := make([]responses.ProductResponse, 0, len(parents))
+	for _, product := range products {
+		if product.ParentID != nil && parents[*product.ParentID] {
+			continue
+		}
+		product.Variants = variants[product.ID]
+		grouped = append(grouped, product)
+	}
+	return grouped, nil
+}
+
+// productExample es la fila de ejemplo de la plantilla vacía
+var productExample = []interface{}{
+	"ABC123",
+	"SKU001",
+	"Lapicera azul trazo fino",
+	25,
+	"Lapicera trazo fino de color azul",
+	"Lapiceras",
+	"Bic",
+	100,
+	150.5,
+	"Distribuidora Norte",
+}
+
+// exportCatalog devuelve los productos a exportar con su precio vigente, o
+// ninguno si se pidió la plantilla vacía.
+func (s *productService) exportCatalog(options requests.ProductExportOptions) ([]responses.ProductExportRow, error) {
+	if options.Template {
+		return nil, nil
+	}
+
+	products, err := s.productRepo.FindForExport(options.CategoryID, options.BrandID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener productos: %v", err)
+	}
+
+	priced := make([]models.Product, 0, len(products))
+	for _, product := range products {
+		priced = append(priced, models.Product{Model: gorm.Model{ID: product.ID}, ProfitMargin: product.ProfitMargin, PriceOverride: product.PriceOverride})
+	}
+	prices, err := s.pricingService.UnitPrices(priced)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].Price = utils.RoundMoney(prices[products[i].ID])
+	}
 	return products, nil
 }
 
-func (s *productService) ExportToExcel() (*excelize.File, error) {
+// ExportToCSV escribe las filas en writer a medida que las arma, sin juntar
+// todo el archivo en memoria.
+func (s *productService) ExportToCSV(writer io.Writer, options requests.ProductExportOptions) error {
+	products, err := s.exportCatalog(options)
+	if err != nil {
+		return err
+	}
+
+	w := newCSVWriter(writer, options.CSVOptions)
+	write := func(row []string) error {
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("error al escribir el CSV: %v", err)
+		}
+		return nil
+	}
+
+	if err := write(append(append([]string{}, productImportHeaders...), productExportInfoHeaders...)); err != nil {
+		return err
+	}
+
+	if options.Template {
+		example := make([]string, len(productExample))
+		for i, value := range productExample {
+			if number, ok := value.(float64); ok {
+				example[i] = formatDecimal(number, options.DecimalComma)
+			} else {
+				example[i] = fmt.Sprint(value)
+			}
+		}
+		if err := write(example); err != nil {
+			return err
+		}
+	}
+
+	for _, product := range products {
+		// Las columnas de saldo inicial quedan vacías: el producto ya existe
+		err := write([]string{
+			product.Code,
+			product.Sku,
+			product.Name,
+			formatDecimal(product.ProfitMargin, options.DecimalComma),
+			product.Description,
+			product.CategoryName,
+			product.BrandName,
+			"",
+			"",
+			"",
+			strconv.Itoa(product.Stock),
+			formatDecimal(product.Price, options.DecimalComma),
+			formatDecimal(product.LastCost, options.DecimalComma),
+			product.LastSupplier,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error al escribir el CSV: %v", err)
+	}
+	return nil
+}
+
+// ExportToExcel escribe el libro en writer. La hoja de productos se arma con
+// un StreamWriter, que no guarda cada celda en memoria.
+func (s *productService) ExportToExcel(writer io.Writer, options requests.ProductExportOptions) error {
+	products, err := s.exportCatalog(options)
+	if err != nil {
+		return err
+	}
 
 	f := excelize.NewFile()
-	sheet := "Products"
+	defer f.Close()
+	sheet := productImportSheet
 	f.SetSheetName("Sheet1", sheet)
-	headers := []string{"CÓDIGO", "SKU", "NOMBRE", "MARGEN DE GANANCIA (%)", "DESCRIPCION", "CATEGORÍA", "MARCA"}
-
-	f.SetColWidth(sheet, "A", "B", 10)
-	f.SetColWidth(sheet, "C", "E", 30)
-	f.SetColWidth(sheet, "F", "G", 15)
 
-	style, err := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{
-			Bold:  true,
-			Color: "#FFFFFF",
-		},
-		Fill: excelize.Fill{
-			Type:    "pattern",
-			Color:   []string{"#576CBC"},
-			Pattern: 1,
-		},
-		Border: []excelize.Border{
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-		},
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-	})
+	style, err := f.NewStyle(headerStyle("#576CBC"))
 	if err != nil {
-		return nil, fmt.Errorf("error al crear estilo para encabezados: %v", err)
+		return fmt.Errorf("error al crear estilo para encabezados: %v", err)
+	}
+	infoStyle, err := f.NewStyle(headerStyle("#7F7F7F"))
+	if err != nil {
+		return fmt.Errorf("error al crear estilo para encabezados: %v", err)
 	}
 
-	for i, h := range headers {
+	header := make([]interface{}, 0, len(productImportHeaders)+len(productExportInfoHeaders))
+	for i, h := range productImportHeaders {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheet, cell, h)
-		f.SetCellStyle(sheet, cell, cell, style)
+		header = append(header, excelize.Cell{StyleID: style, Value: h})
 
 		if h == "SKU" {
 			f.AddComment(sheet, excelize.Comment{
@@ -102,184 +230,301 @@ func (s *productService) ExportToExcel() (*excelize.File, error) {
 				Cell:   cell,
 			})
 		}
+
+		if h == "STOCK INICIAL" {
+			f.AddComment(sheet, excelize.Comment{
+				Author: "Sistema",
+				Text:   "Opcional. Solo se carga en productos nuevos; si es mayor a 0 requiere costo y proveedor",
+				Cell:   cell,
+			})
+		}
 	}
 
-	productExample := []interface{}{
-		"ABC123",
-		"SKU001",
-		"Lapicera azul trazo fino",
-		25,
-		"Lapicera trazo fino de color azul",
-		"Lapiceras",
-		"Bic",
+	// Columnas informativas a la derecha de las que lee el importador
+	for i, h := range productExportInfoHeaders {
+		cell, _ := excelize.CoordinatesToCellName(len(productImportHeaders)+i+1, 1)
+		header = append(header, excelize.Cell{StyleID: infoStyle, Value: h})
+		f.AddComment(sheet, excelize.Comment{
+			Author: "Sistema",
+			Text:   "Solo informativo, se ignora al importar",
+			Cell:   cell,
+		})
 	}
-	f.SetSheetRow(sheet, "A2", &productExample)
 
-	_, _ = f.NewSheet("Categories")
+	lists := []struct {
+		sheet  string
+		column string
+		label  string
+		values func(string) ([]string, error)
+	}{
+		{"Categories", "F", "categorías", s.categoryOps.Pluck},
+		{"Brands", "G", "marcas", s.brandOps.Pluck},
+		{"Suppliers", "J", "proveedores", s.supplierOps.Pluck},
+	}
 
-	categories, err := s.categoryOps.Pluck("name")
+	// Las listas desplegables cubren el catálogo exportado y 100 filas más para cargar
+	lastRow := len(products) + 101
+	for _, list := range lists {
+		_, _ = f.NewSheet(list.sheet)
 
-	if err != nil {
-		return nil, fmt.Errorf("error al obtener categorías: %v", err)
-	}
+		values, err := list.values("name")
+		if err != nil {
+			return fmt.Errorf("error al obtener %s: %v", list.label, err)
+		}
 
-	for i, category := range categories {
-		cell, _ := excelize.CoordinatesToCellName(1, i+1)
-		f.SetCellValue("Categories", cell, category)
-	}
+		for i, value := range values {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			f.SetCellValue(list.sheet, cell, value)
+		}
 
-	_, _ = f.NewSheet("Brands")
+		if len(values) > 0 {
+			dv := excelize.NewDataValidation(true)
+			dv.SetSqref(fmt.Sprintf("%s2:%s%d", list.column, list.column, lastRow))
+			dv.SetSqrefDropList(fmt.Sprintf("%s!$A$1:$A$%d", list.sheet, len(values)))
+			if err = f.AddDataValidation(sheet, dv); err != nil {
+				return fmt.Errorf("error en validación de %s: %v", list.label, err)
+			}
+		}
 
-	brands, err := s.brandOps.Pluck("name")
+		// Ocultar hojas auxiliares
+		_ = f.SetSheetVisible(list.sheet, false)
+	}
 
+	// Los comentarios y las validaciones ya están cargados: el StreamWriter los
+	// conserva al cerrar la hoja
+	sw, err := f.NewStreamWriter(sheet)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener marcas: %v", err)
+		return fmt.Errorf("error al crear la hoja de productos: %v", err)
 	}
 
-	for i, brand := range brands {
-		cell, _ := excelize.CoordinatesToCellName(1, i+1)
-		f.SetCellValue("Brands", cell, brand)
+	widths := []struct {
+		min, max int
+		width    float64
+	}{
+		{1, 2, 10},   // A:B
+		{3, 5, 30},   // C:E
+		{6, 7, 15},   // F:G
+		{8, 9, 12},   // H:I
+		{10, 10, 20}, // J
+		{11, 14, 15}, // K:N
+	}
+	for _, column := range widths {
+		if err := sw.SetColWidth(column.min, column.max, column.width); err != nil {
+			return fmt.Errorf("error al configurar las columnas: %v", err)
+		}
 	}
 
-	for row := 2; row <= 101; row++ {
-		categoryCell, _ := excelize.CoordinatesToCellName(6, row) // Columna F (Category)
-		brandCell, _ := excelize.CoordinatesToCellName(7, row)    // Columna G (Brand)
+	if err := sw.SetRow("A1", header); err != nil {
+		return fmt.Errorf("error al escribir los encabezados: %v", err)
+	}
 
-		dv1 := excelize.NewDataValidation(true)
-		dv1.SetSqref(categoryCell)
-		dv1.SetSqrefDropList("Categories!$A$1:$A$100")
-		if err = f.AddDataValidation(sheet, dv1); err != nil {
-			return nil, fmt.Errorf("error en validación categoría fila %d: %v", row, err)
+	firstRow := 2
+	if options.Template {
+		if err := sw.SetRow("A2", productExample); err != nil {
+			return fmt.Errorf("error al escribir la fila de ejemplo: %v", err)
 		}
+		firstRow++
+	}
 
-		dv2 := excelize.NewDataValidation(true)
-		dv2.SetSqref(brandCell)
-		dv2.SetSqrefDropList("Brands!$A$1:$A$100")
-
-		if err = f.AddDataValidation(sheet, dv2); err != nil {
-			return nil, fmt.Errorf("error en validación marca fila %d: %v", row, err)
+	for i, product := range products {
+		// Las columnas de saldo inicial quedan vacías: el producto ya existe
+		row := []interface{}{
+			product.Code,
+			product.Sku,
+			product.Name,
+			product.ProfitMargin,
+			product.Description,
+			product.CategoryName,
+			product.BrandName,
+			nil,
+			nil,
+			nil,
+			product.Stock,
+			product.Price,
+			product.LastCost,
+			product.LastSupplier,
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, firstRow+i)
+		if err := sw.SetRow(cell, row); err != nil {
+			return fmt.Errorf("error al escribir la fila %d: %v", firstRow+i, err)
 		}
 	}
 
-	// Ocultar hojas auxiliares
-	_ = f.SetSheetVisible("Categories", false)
-	_ = f.SetSheetVisible("Brands", false)
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("error al escribir la hoja de productos: %v", err)
+	}
 
 	// Set "Products" como hoja activa
 	index, _ := f.GetSheetIndex(sheet)
 	f.SetActiveSheet(index)
 
-	return f, nil
+	if err := f.Write(writer); err != nil {
+		return fmt.Errorf("error al generar el archivo Excel: %v", err)
+	}
+	return nil
 }
 
-func (s *productService) ImportFromExcel(reader io.Reader) error {
-	f, err := excelize.OpenReader(reader)
+func headerStyle(color string) *excelize.Style {
+	return &excelize.Style{
+		Font: &excelize.Font{
+			Bold:  true,
+			Color: "#FFFFFF",
+		},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{color},
+			Pattern: 1,
+		},
+		Border: []excelize.Border{
+			{Type: "left", Color: "000000", Style: 1},
+			{Type: "top", Color: "000000", Style: 1},
+			{Type: "right", Color: "000000", Style: 1},
+			{Type: "bottom", Color: "000000", Style: 1},
+		},
+		Alignment: &excelize.Alignment{
+			Horizontal: "center",
+			Vertical:   "center",
+		},
+	}
+}
+
+func (s *productService) FindByBarcode(code string) (responses.ProductLookupResponse, error) {
+	code = strings.TrimSpace(code)
+	product, err := s.productRepo.FindByBarcode(utils.BarcodeVariants(code))
 	if err != nil {
-		return fmt.Errorf("no se pudo abrir el archivo: %v", err)
+		return responses.ProductLookupResponse{}, fmt.Errorf("no hay productos con el código de barras '%s'", code)
 	}
 
-	rows, err := f.GetRows("Products")
+	product.Price, err = s.pricingService.UnitPrice(models.Product{Model: gorm.Model{ID: product.ID}, ProfitMargin: product.ProfitMargin, PriceOverride: product.PriceOverride})
 	if err != nil {
-		return fmt.Errorf("no se pudo leer la hoja 'Products': %v", err)
+		return responses.ProductLookupResponse{}, err
 	}
 
-	if len(rows) < 2 {
-		return fmt.Errorf("el archivo no contiene datos")
+	// El stock de un combo es la cantidad que se puede armar con sus componentes
+	available, err := availableQuantities(s.db, []uint{product.ID})
+	if err != nil {
+		return responses.ProductLookupResponse{}, err
 	}
+	product.Stock = available[product.ID]
+	return product, nil
+}
+
+func (s *productService) GetBarcodes(productID uint) ([]models.ProductBarcode, error) {
+	return s.productRepo.FindBarcodes(productID)
+}
 
-	categoryMap := map[string]uint{}
-	categories, _ := s.categoryOps.FindAll()
-	for _, c := range categories {
-		categoryMap[c.Name] = c.ID
+func (s *productService) AddBarcode(productID uint, request requests.ProductBarcodeRequest) (models.ProductBarcode, error) {
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil {
+		return models.ProductBarcode{}, fmt.Errorf("producto con ID %d no encontrado", productID)
 	}
 
-	brandMap := map[string]uint{}
-	brands, _ := s.brandOps.FindAll()
-	for _, b := range brands {
-		brandMap[b.Name] = b.ID
+	if err := request.Validate(s.db); err != nil {
+		return models.ProductBarcode{}, err
 	}
 
-	var validProducts []models.Product
-	var errors []string
+	barcode, err := request.ToModel()
+	if err != nil {
+		return models.ProductBarcode{}, err
+	}
+	barcode.ProductID = productID
 
-	for i, row := range rows[1:] { // saltar encabezado (fila 0)
-		rowNum := i + 2 // fila real en Excel
+	if err := s.productRepo.CreateBarcode(&barcode); err != nil {
+		return models.ProductBarcode{}, err
+	}
+	return barcode, nil
+}
 
-		if len(row) < 7 {
-			errors = append(errors, fmt.Sprintf("Fila %d: Faltan columnas", rowNum))
-			continue
-		}
+func (s *productService) RemoveBarcode(productID uint, barcodeID string) error {
+	return s.productRepo.DeleteBarcode(productID, barcodeID)
+}
 
-		code := strings.TrimSpace(row[0])
-		sku := strings.TrimSpace(row[1])
-		name := strings.TrimSpace(row[2])
-		profitMarginStr := strings.TrimSpace(row[3])
-		description := strings.TrimSpace(row[4])
-		categoryName := strings.TrimSpace(row[5])
-		brandName := strings.TrimSpace(row[6])
-
-		// Validaciones básicas
-		if code == "" || name == "" || profitMarginStr == "" || categoryName == "" || brandName == "" {
-			errors = append(errors, fmt.Sprintf("Fila %d: Campos obligatorios faltantes", rowNum))
-			continue
-		}
+// Search busca productos por nombre, descripción, código, SKU, código de
+// barras, categoría y marca, sin importar tildes ni mayúsculas.
+func (s *productService) Search(options requests.ProductSearchOptions) ([]responses.ProductSearchResult, error) {
+	term := utils.NormalizeName(options.Query)
 
-		profitMargin, err := strconv.ParseFloat(profitMarginStr, 64)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Fila %d: Margen de ganancia inválido", rowNum))
-			continue
-		}
+	// Cada palabra se busca también como prefijo, para encontrar mientras se escribe
+	words := strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil, fmt.Errorf("la búsqueda debe tener al menos una letra o un número")
+	}
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
 
-		if profitMargin < 0 || profitMargin > 100 {
-			errors = append(errors, fmt.Sprintf("Fila %d: Margen de ganancia debe estar entre 0 y 100", rowNum))
-			continue
-		}
+	limit := options.Limit
+	if limit == 0 {
+		limit = 20
+	}
 
-		if exists, err := s.productRepo.ExistsByCodeAndName(code, name); err != nil || exists {
-			errors = append(errors, fmt.Sprintf("Fila %d: Producto con código '%s' y nombre '%s' ya existe", rowNum, code, name))
-			continue
-		}
-		if sku != "" {
-			if exists, err := s.productRepo.ExistsBySku(sku); err != nil || exists {
-				errors = append(errors, fmt.Sprintf("Fila %d: SKU '%s' ya existe", rowNum, sku))
-				continue
-			}
-		}
+	results, err := s.productRepo.Search(term, strings.Join(words, " & "), utils.BarcodeVariants(strings.TrimSpace(options.Query)), limit)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-		categoryID, ok := categoryMap[categoryName]
-		if !ok {
-			err := fmt.Sprintf("fila %d: Categoría '%s' no encontrada", rowNum, categoryName)
-			errors = append(errors, err)
-			continue
-		}
+func (s *productService) GetVariants(parentID uint) ([]models.Product, error) {
+	if _, err := s.findParent(parentID); err != nil {
+		return nil, err
+	}
+	return s.productRepo.FindVariants(parentID)
+}
 
-		brandID, ok := brandMap[brandName]
-		if !ok {
-			err := fmt.Sprintf("Fila %d: Marca '%s' no encontrada", rowNum, brandName)
-			errors = append(errors, err)
-			continue
-		}
+func (s *productService) CreateVariant(parentID uint, request requests.ProductVariantRequest) (models.Product, error) {
+	parent, err := s.findParent(parentID)
+	if err != nil {
+		return models.Product{}, err
+	}
 
-		p := models.Product{
-			Code:         code,
-			Sku:          sku,
-			Name:         name,
-			ProfitMargin: profitMargin,
-			Description:  description,
-			CategoryID:   categoryID,
-			BrandID:      brandID,
-		}
-		validProducts = append(validProducts, p)
+	if err := request.Validate(s.db, parent, 0); err != nil {
+		return models.Product{}, err
+	}
+
+	variant, err := request.ToModel(parent)
+	if err != nil {
+		return models.Product{}, err
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("errores encontrados:\n%s", strings.Join(errors, "\n"))
+	if err := s.productRepo.Save(&variant); err != nil {
+		return models.Product{}, err
 	}
+	return variant, nil
+}
 
-	if _, err := s.productRepo.CreateMany(validProducts); err != nil {
-		return fmt.Errorf("error al guardar productos: %v", err)
+func (s *productService) UpdateVariant(parentID uint, variantID string, request requests.ProductVariantRequest) (models.Product, error) {
+	parent, err := s.findParent(parentID)
+	if err != nil {
+		return models.Product{}, err
 	}
 
-	return nil
+	var variant models.Product
+	if err := s.db.Where("parent_id = ?", parentID).First(&variant, variantID).Error; err != nil {
+		return models.Product{}, fmt.Errorf("variante con ID %s no encontrada", variantID)
+	}
+
+	if err := request.Validate(s.db, parent, variant.ID); err != nil {
+		return models.Product{}, err
+	}
+
+	variant, err = request.UpdateModel(parent, variant)
+	if err != nil {
+		return models.Product{}, err
+	}
 
+	if err := s.productRepo.Save(&variant); err != nil {
+		return models.Product{}, err
+	}
+	return variant, nil
+}
+
+func (s *productService) findParent(parentID uint) (models.Product, error) {
+	var parent models.Product
+	if err := s.db.First(&parent, parentID).Error; err != nil {
+		return models.Product{}, fmt.Errorf("producto con ID %d no encontrado", parentID)
+	}
+	return parent, nil
 }
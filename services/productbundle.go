@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+
+	"gorm.io/gorm"
+)
+
+type ProductBundleService interface {
+	GetByProduct(productID uint) (responses.ProductBundleResponse, error)
+	Save(productID uint, request requests.ProductBundleRequest) (responses.ProductBundleResponse, error)
+	Delete(productID uint) error
+}
+
+type productBundleService struct {
+	db             *gorm.DB
+	bundleRepo     repositories.ProductBundleRepository
+	pricingService PricingService
+}
+
+func NewProductBundleService(db *gorm.DB, bundleRepo repositories.ProductBundleRepository, pricingService PricingService) ProductBundleService {
+	return &productBundleService{
+		db:             db,
+		bundleRepo:     bundleRepo,
+		pricingService: pricingService,
+	}
+}
+
+// GetByProduct devuelve el combo con su precio vigente y cuántos se pueden
+// armar con el stock actual de los componentes.
+func (s *productBundleService) GetByProduct(productID uint) (responses.ProductBundleResponse, error) {
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil {
+		return responses.ProductBundleResponse{}, fmt.Errorf("producto con ID %d no encontrado", productID)
+	}
+
+	bundle, err := s.bundleRepo.FindByProduct(productID)
+	if err != nil {
+		return responses.ProductBundleResponse{}, fmt.Errorf("el producto %d no es un combo", productID)
+	}
+
+	price, err := s.pricingService.UnitPrice(product)
+	if err != nil {
+		return responses.ProductBundleResponse{}, err
+	}
+
+	available, err := availableQuantities(s.db, []uint{productID})
+	if err != nil {
+		return responses.ProductBundleResponse{}, err
+	}
+
+	return responses.ProductBundleResponse{
+		ProductBundle: bundle,
+		Price:         price,
+		Available:     available[productID],
+	}, nil
+}
+
+func (s *productBundleService) Save(productID uint, request requests.ProductBundleRequest) (responses.ProductBundleResponse, error) {
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil {
+		return responses.ProductBundleResponse{}, fmt.Errorf("producto con ID %d no encontrado", productID)
+	}
+
+	if err := request.Validate(s.db, productID); err != nil {
+		return responses.ProductBundleResponse{}, err
+	}
+
+	bundle, err := s.bundleRepo.FindByProduct(productID)
+	if err == gorm.ErrRecordNotFound {
+		bundle, err = request.ToModel(productID)
+	} else if err == nil {
+		bundle, err = request.UpdateModel(bundle)
+	}
+	if err != nil {
+		return responses.ProductBundleResponse{}, err
+	}
+
+	if err := s.bundleRepo.Save(&bundle); err != nil {
+		return responses.ProductBundleResponse{}, err
+	}
+	return s.GetByProduct(productID)
+}
+
+// Delete deja de tratar al producto como combo; las ventas ya registradas
+// conservan la composición con la que se vendieron.
+func (s *productBundleService) Delete(productID uint) error {
+	bundle, err := s.bundleRepo.FindByProduct(productID)
+	if err != nil {
+		return fmt.Errorf("el producto %d no es un combo", productID)
+	}
+	return s.bundleRepo.Delete(&bundle)
+}
+
+// findBundle devuelve el combo del producto, o nil si el producto no es un combo.
+func findBundle(db *gorm.DB, productID uint) (*models.ProductBundle, error) {
+	bundle, err := repositories.NewProductBundleRepository(db).FindByProduct(productID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// findBundles es findBundle para varios productos, indexado por producto.
+func findBundles(db *gorm.DB, productIDs []uint) (map[uint]models.ProductBundle, error) {
+	bundles, err := repositories.NewProductBundleRepository(db).FindByProducts(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint]models.ProductBundle, len(bundles))
+	for _, bundle := range bundles {
+		byProduct[bundle.ProductID] = bundle
+	}
+	return byProduct, nil
+}
+
+// bundleAvailability calcula cuántos combos completos alcanzan con el stock de
+// los componentes: el mínimo entre el stock de cada uno y la cantidad que lleva.
+func bundleAvailability(bundle models.ProductBundle, stocks map[uint]int) int {
+	available := -1
+	for _, component := range bundle.Components {
+		units := max(stocks[component.ProductID], 0) / component.Quantity
+		if available < 0 || units < available {
+			available = units
+		}
+	}
+	return max(available, 0)
+}
+
+// availableQuantities devuelve el stock disponible de cada producto. Para los
+// combos es la cantidad que se puede armar con el stock de sus componentes.
+func availableQuantities(db *gorm.DB, productIDs []uint) (map[uint]int, error) {
+	stockRepo := repositories.NewProductStockRepository(db)
+	quantities, err := stockRepo.Quantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, productID := range productIDs {
+		bundle, err := findBundle(db, productID)
+		if err != nil {
+			return nil, err
+		}
+		if bundle == nil {
+			continue
+		}
+
+		componentIDs := make([]uint, 0, len(bundle.Components))
+		for _, component := range bundle.Components {
+			componentIDs = append(componentIDs, component.ProductID)
+		}
+		stocks, err := stockRepo.Quantities(componentIDs)
+		if err != nil {
+			return nil, err
+		}
+		quantities[productID] = bundleAvailability(*bundle, stocks)
+	}
+	return quantities, nil
+}
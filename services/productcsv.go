@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"libreria/requests"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func readCSV(reader io.Reader, options requests.CSVOptions) ([][]string, error) {
+	if options.IsWindows1252() {
+		reader = charmap.Windows1252.NewDecoder().Reader(reader)
+	}
+
+	r := csv.NewReader(reader)
+	r.Comma = options.Comma()
+	r.FieldsPerRecord = -1 // las filas pueden tener menos columnas que el encabezado
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el CSV: %v", err)
+	}
+
+	// Excel agrega un BOM al guardar como "CSV UTF-8"
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
+
+// newCSVWriter escribe filas en writer a medida que se agregan, con el
+// separador y la codificación pedidos.
+func newCSVWriter(writer io.Writer, options requests.CSVOptions) *csv.Writer {
+	if options.IsWindows1252() {
+		// Los caracteres que no existen en Windows-1252 se reemplazan en lugar de cortar la exportación
+		writer = encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()).Writer(writer)
+	}
+
+	w := csv.NewWriter(writer)
+	w.Comma = options.Comma()
+	return w
+}
+
+// parseDecimal acepta "1.234,56" cuando el archivo usa coma decimal.
+func parseDecimal(value string, decimalComma bool) (float64, error) {
+	if decimalComma {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func formatDecimal(value float64, decimalComma bool) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if decimalComma {
+		formatted = strings.Replace(formatted, ".", ",", 1)
+	}
+	return formatted
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	stddraw "image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/storage"
+	"log"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+type ProductImageService interface {
+	Upload(productID uint, reader io.Reader) (models.ProductImage, error)
+	GetByProduct(productID uint) ([]models.ProductImage, error)
+	Delete(productID uint, imageID string) error
+	Open(imageID string, thumbnail bool) (io.ReadCloser, string, error)
+	Thumbnails(productIDs []uint) (map[uint][]byte, error)
+}
+
+type productImageService struct {
+	db        *gorm.DB
+	imageRepo repositories.ProductImageRepository
+	storage   storage.Storage
+}
+
+const (
+	maxImageSize  = 10 << 20 // 10 MB
+	thumbnailSize = 300      // lado máximo en píxeles
+)
+
+// Formatos aceptados, según lo que devuelve image.Decode
+var imageContentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+var imageExtensions = map[string]string{
+	"jpeg": "jpg",
+	"png":  "png",
+	"gif":  "gif",
+	"webp": "webp",
+}
+
+func NewProductImageService(db *gorm.DB, imageRepo repositories.ProductImageRepository, storage storage.Storage) ProductImageService {
+	return &productImageService{
+		db:        db,
+		imageRepo: imageRepo,
+		storage:   storage,
+	}
+}
+
+// Upload guarda la imagen original y una miniatura en JPEG.
+func (s *productImageService) Upload(productID uint, reader io.Reader) (models.ProductImage, error) {
+	var product models.Product
+	if err := s.db.First(&product, productID).Error; err != nil {
+		return models.ProductImage{}, fmt.Errorf("producto con ID %d no encontrado", productID)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxImageSize+1))
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("no se pudo leer la imagen: %v", err)
+	}
+	if len(content) > maxImageSize {
+		return models.ProductImage{}, fmt.Errorf("la imagen no puede superar los %d MB", maxImageSize>>20)
+	}
+
+	decoded, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return models.ProductImage{}, errors.New("el archivo no es una imagen JPEG, PNG, GIF o WebP válida")
+	}
+	contentType, ok := imageContentTypes[format]
+	if !ok {
+		return models.ProductImage{}, fmt.Errorf("formato de imagen '%s' no soportado", format)
+	}
+
+	thumbnail, err := makeThumbnail(decoded)
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("no se pudo generar la miniatura: %v", err)
+	}
+
+	name := uuid.NewString()
+	productImage := models.ProductImage{
+		ProductID:    productID,
+		Key:          fmt.Sprintf("products/%d/%s.%s", productID, name, imageExtensions[format]),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb.jpg", productID, name),
+		ContentType:  contentType,
+		Width:        decoded.Bounds().Dx(),
+		Height:       decoded.Bounds().Dy(),
+		Size:         int64(len(content)),
+	}
+
+	if err := s.storage.Put(productImage.Key, content, contentType); err != nil {
+		return models.ProductImage{}, fmt.Errorf("no se pudo guardar la imagen: %v", err)
+	}
+	if err := s.storage.Put(productImage.ThumbnailKey, thumbnail, "image/jpeg"); err != nil {
+		s.removeFiles(productImage)
+		return models.ProductImage{}, fmt.Errorf("no se pudo guardar la miniatura: %v", err)
+	}
+
+	position, err := s.imageRepo.NextPosition(productID)
+	if err == nil {
+		productImage.Position = position
+		err = s.imageRepo.Create(&productImage)
+	}
+	if err != nil {
+		s.removeFiles(productImage)
+		return models.ProductImage{}, err
+	}
+	return productImage, nil
+}
+
+func (s *productImageService) GetByProduct(productID uint) ([]models.ProductImage, error) {
+	return s.imageRepo.FindByProduct(productID)
+}
+
+func (s *productImageService) Delete(productID uint, imageID string) error {
+	productImage, err := s.imageRepo.FindByID(imageID)
+	if err != nil || productImage.ProductID != productID {
+		return fmt.Errorf("imagen con ID %s no encontrada", imageID)
+	}
+
+	if err := s.imageRepo.Delete(&productImage); err != nil {
+		return err
+	}
+	s.removeFiles(productImage)
+	return nil
+}
+
+// Open devuelve el contenido de la imagen o de su miniatura y su tipo.
+func (s *productImageService) Open(imageID string, thumbnail bool) (io.ReadCloser, string, error) {
+	productImage, err := s.imageRepo.FindByID(imageID)
+	if err != nil {
+		return nil, "", fmt.Errorf("imagen con ID %s no encontrada", imageID)
+	}
+
+	key, contentType := productImage.Key, productImage.ContentType
+	if thumbnail {
+		key, contentType = productImage.ThumbnailKey, "image/jpeg"
+	}
+
+	content, err := s.storage.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, "", fmt.Errorf("imagen con ID %s no encontrada", imageID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return content, contentType, nil
+}
+
+// Thumbnails devuelve la miniatura de la imagen principal de cada producto.
+// Los productos sin imagen, o cuyo archivo no se pudo leer, no aparecen.
+func (s *productImageService) Thumbnails(productIDs []uint) (map[uint][]byte, error) {
+	primary, err := s.imageRepo.FindPrimary(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails := make(map[uint][]byte, len(primary))
+	for productID, productImage := range primary {
+		content, err := s.storage.Get(productImage.ThumbnailKey)
+		if err != nil {
+			log.Printf("No se pudo leer la miniatura %d: %v", productImage.ID, err)
+			continue
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			log.Printf("No se pudo leer la miniatura %d: %v", productImage.ID, err)
+			continue
+		}
+		thumbnails[productID] = data
+	}
+	return thumbnails, nil
+}
+
+func (s *productImageService) removeFiles(productImage models.ProductImage) {
+	for _, key := range []string{productImage.Key, productImage.ThumbnailKey} {
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("No se pudo borrar el archivo '%s': %v", key, err)
+		}
+	}
+}
+
+// makeThumbnail reduce la imagen para que entre en thumbnailSize x
+// thumbnailSize, sobre fondo blanco para las imágenes con transparencia.
+func makeThumbnail(source image.Image) ([]byte, error) {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			height = max(height*thumbnailSize/width, 1)
+			width = thumbnailSize
+		} else {
+			width = max(width*thumbnailSize/height, 1)
+			height = thumbnailSize
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	stddraw.Draw(thumbnail, thumbnail.Bounds(), image.White, image.Point{}, stddraw.Src)
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
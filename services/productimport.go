@@ -0,0 +1,775 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"libreria/utils"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const productImportSheet = "Products"
+
+var productImportHeaders = []string{"CÓDIGO", "SKU", "NOMBRE", "MARGEN DE GANANCIA (%)", "DESCRIPCION", "CATEGORÍA", "MARCA", "STOCK INICIAL", "COSTO", "PROVEEDOR"}
+
+// Posición de cada columna en la hoja de importación
+const (
+	importColCode = iota
+	importColSku
+	importColName
+	importColProfitMargin
+	importColDescription
+	importColCategory
+	importColBrand
+	importColStock
+	importColCost
+	importColSupplier
+)
+
+// Columnas que el archivo tiene que traer; las demás son opcionales
+var productImportRequired = []int{importColCode, importColName, importColProfitMargin, importColCategory, importColBrand}
+
+// Largos máximos según las columnas de models.Product
+var productImportLengths = []struct{ col, max int }{
+	{importColCode, 20},
+	{importColSku, 20},
+	{importColName, 65},
+	{importColDescription, 150},
+	{importColSupplier, 65},
+}
+
+// Columnas que agrega la exportación del catálogo y que el importador ignora
+var productExportInfoHeaders = []string{"STOCK ACTUAL", "PRECIO", "ÚLTIMO COSTO", "ÚLTIMO PROVEEDOR"}
+
+const openingBalanceNote = "Stock inicial (importación)"
+
+// importedSupplierContact completa el contacto, que es obligatorio, de los
+// proveedores que crea la importación; así se pueden editar después sin
+// inventar un dato.
+const importedSupplierContact = "Sin datos de contacto (creado por importación)"
+
+func (s *productService) ImportProducts(reader io.Reader, options requests.ProductImportOptions, progress ImportProgress) (responses.ProductImportReport, error) {
+	var raw [][]string
+	if options.IsCSV() {
+		rows, err := readCSV(reader, options.CSVOptions)
+		if err != nil {
+			return responses.ProductImportReport{}, err
+		}
+		raw = rows
+	} else {
+		f, err := excelize.OpenReader(reader)
+		if err != nil {
+			return responses.ProductImportReport{}, fmt.Errorf("no se pudo abrir el archivo: %v", err)
+		}
+		defer f.Close()
+
+		sheet := productSheetName(f)
+		raw, err = f.GetRows(sheet)
+		if err != nil {
+			return responses.ProductImportReport{}, fmt.Errorf("no se pudo leer la hoja '%s': %v", sheet, err)
+		}
+	}
+
+	table, err := newProductTable(raw, options.IsCSV() && options.DecimalComma)
+	if err != nil {
+		return responses.ProductImportReport{}, err
+	}
+
+	plan, err := s.validateProductRows(table, options, progress)
+	if err != nil {
+		return responses.ProductImportReport{}, err
+	}
+	report := plan.report
+	report.DryRun = options.DryRun
+
+	// Si alguna fila tiene errores no se guarda ninguna
+	if options.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		categoryIDs, err := createNamed(tx, plan.newCategories, func(name string) *models.Category {
+			return &models.Category{Name: name}
+		}, func(c *models.Category) uint { return c.ID })
+		if err != nil {
+			return fmt.Errorf("error al crear categorías: %v", err)
+		}
+		brandIDs, err := createNamed(tx, plan.newBrands, func(name string) *models.Brand {
+			return &models.Brand{Name: name}
+		}, func(b *models.Brand) uint { return b.ID })
+		if err != nil {
+			return fmt.Errorf("error al crear marcas: %v", err)
+		}
+
+		supplierIDs, err := createNamed(tx, plan.newSuppliers, func(name string) *models.Supplier {
+			return &models.Supplier{Name: name, ContactInfo: importedSupplierContact}
+		}, func(s *models.Supplier) uint { return s.ID })
+		if err != nil {
+			return fmt.Errorf("error al crear proveedores: %v", err)
+		}
+
+		toCreate := plan.resolve(plan.toCreate, categoryIDs, brandIDs)
+		toUpdate := plan.resolve(plan.toUpdate, categoryIDs, brandIDs)
+
+		// El avance de la escritura se cuenta por producto guardado
+		written, toWrite := 0, len(toCreate)+len(toUpdate)
+		reportSaved := func(count int) {
+			written += count
+			if progress != nil {
+				progress(constants.IMPORT_JOB_PHASE_SAVING, written, toWrite)
+			}
+		}
+		if progress != nil {
+			progress(constants.IMPORT_JOB_PHASE_SAVING, 0, toWrite)
+		}
+
+		productRepo := repositories.NewProductRepository(tx)
+		if len(toCreate) > 0 {
+			if _, err := productRepo.CreateMany(toCreate); err != nil {
+				return fmt.Errorf("error al guardar productos: %v", err)
+			}
+		}
+		// CreateMany completa los IDs, así que el saldo inicial puede referenciarlos
+		for i, item := range plan.toCreate {
+			if item.openingStock <= 0 {
+				continue
+			}
+			supplierID := item.supplierID
+			if item.supplierKey != "" {
+				supplierID = supplierIDs[item.supplierKey]
+			}
+			if err := createOpeningBalance(tx, toCreate[i].ID, supplierID, item.openingStock, item.openingCost); err != nil {
+				return fmt.Errorf("error al cargar el stock inicial de '%s': %v", toCreate[i].Code, err)
+			}
+		}
+		reportSaved(len(toCreate))
+		for i := range toUpdate {
+			if err := productRepo.UpdateCatalogFields(&toUpdate[i]); err != nil {
+				return fmt.Errorf("error al actualizar el producto '%s': %v", toUpdate[i].Code, err)
+			}
+			reportSaved(1)
+		}
+		return nil
+	})
+	if err != nil {
+		return responses.ProductImportReport{}, err
+	}
+	report.Created = len(plan.toCreate)
+	report.Updated = len(plan.toUpdate)
+
+	return report, nil
+}
+
+// AnnotateImportErrors devuelve el mismo archivo con las celdas con errores
+// resaltadas y un comentario que explica cada problema.
+func (s *productService) AnnotateImportErrors(reader io.Reader, options requests.ProductImportOptions) (*excelize.File, error) {
+	if options.IsCSV() {
+		return nil, fmt.Errorf("el archivo con errores marcados solo está disponible para Excel")
+	}
+
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el archivo: %v", err)
+	}
+
+	sheet := productSheetName(f)
+	raw, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la hoja '%s': %v", sheet, err)
+	}
+	table, err := newProductTable(raw, false)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.validateProductRows(table, options, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := plan.report
+
+	style, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#F8CBAD"},
+			Pattern: 1,
+		},
+		Border: []excelize.Border{
+			{Type: "left", Color: "C00000", Style: 1},
+			{Type: "top", Color: "C00000", Style: 1},
+			{Type: "right", Color: "C00000", Style: 1},
+			{Type: "bottom", Color: "C00000", Style: 1},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al crear estilo para errores: %v", err)
+	}
+
+	// Una celda puede tener más de un error: se juntan en un solo comentario
+	messages := map[string][]string{}
+	var cells []string
+	for _, rowError := range report.Errors {
+		if _, ok := messages[rowError.Cell]; !ok {
+			cells = append(cells, rowError.Cell)
+		}
+		messages[rowError.Cell] = append(messages[rowError.Cell], rowError.Message)
+	}
+
+	for _, cell := range cells {
+		if cell == "" { // columna opcional que no está en el archivo
+			continue
+		}
+		f.SetCellStyle(sheet, cell, cell, style)
+		f.AddComment(sheet, excelize.Comment{
+			Author: "Sistema",
+			Text:   strings.Join(messages[cell], "\n"),
+			Cell:   cell,
+		})
+	}
+
+	return f, nil
+}
+
+// productImportPlan es el resultado de validar la planilla: el reporte y los
+// productos a crear o actualizar si no hubo errores.
+type productImportPlan struct {
+	report        responses.ProductImportReport
+	toCreate      []plannedProduct
+	toUpdate      []plannedProduct
+	newCategories []string
+	newBrands     []string
+	newSuppliers  []string
+}
+
+// plannedProduct guarda el nombre normalizado de la categoría, marca o
+// proveedor cuando todavía no existe y se crea en la misma importación, y el
+// saldo inicial a cargar para los productos nuevos.
+type plannedProduct struct {
+	product      models.Product
+	categoryKey  string
+	brandKey     string
+	supplierKey  string
+	supplierID   uint
+	openingStock int
+	openingCost  float64
+}
+
+// resolve completa los IDs de las categorías y marcas creadas en la importación.
+func (p productImportPlan) resolve(planned []plannedProduct, categoryIDs, brandIDs map[string]uint) []models.Product {
+	products := make([]models.Product, 0, len(planned))
+	for _, item := range planned {
+		product := item.product
+		if item.categoryKey != "" {
+			product.CategoryID = categoryIDs[item.categoryKey]
+		}
+		if item.brandKey != "" {
+			product.BrandID = brandIDs[item.brandKey]
+		}
+		products = append(products, product)
+	}
+	return products
+}
+
+// createNamed crea las entidades a partir de sus nombres y devuelve sus IDs
+// indexados por nombre normalizado.
+func createNamed[T any](tx *gorm.DB, names []string, build func(string) *T, id func(*T) uint) (map[string]uint, error) {
+	ids := make(map[string]uint, len(names))
+	for _, name := range names {
+		model := build(name)
+		if err := tx.Create(model).Error; err != nil {
+			return nil, err
+		}
+		ids[utils.NormalizeName(name)] = id(model)
+	}
+	return ids, nil
+}
+
+// createOpeningBalance registra el saldo inicial como una compra al proveedor
+// indicado, para que el costo promedio tenga de dónde calcularse, y su
+// movimiento de entrada de stock.
+func createOpeningBalance(tx *gorm.DB, productID, supplierID uint, quantity int, cost float64) error {
+	purchase := models.PurchaseHistory{
+		ProductID:  productID,
+		SupplierID: supplierID,
+		Cost:       cost,
+		Quantity:   quantity,
+	}
+	if err := repositories.NewPurchaseHistoryRepository(tx).Create(&purchase); err != nil {
+		return err
+	}
+	if err := applyMovementFlowTx(tx, productID, quantity, constants.STOCK_MOVEMENT_TYPE_IN, purchase.ID, openingBalanceNote); err != nil {
+		return err
+	}
+	return updateLastCost(tx, purchase)
+}
+
+// productTable es el archivo ya leído, con los valores de cada fila en el
+// orden de productImportHeaders sin importar el orden de las columnas.
+type productTable struct {
+	rows         [][]string // nil para las filas vacías
+	columns      []int      // posición en el archivo de cada columna, -1 si no está
+	decimalComma bool
+}
+
+func newProductTable(raw [][]string, decimalComma bool) (productTable, error) {
+	if len(raw) < 2 {
+		return productTable{}, fmt.Errorf("el archivo no contiene datos")
+	}
+
+	columns, err := mapImportColumns(raw[0])
+	if err != nil {
+		return productTable{}, err
+	}
+
+	table := productTable{
+		rows:         make([][]string, len(raw)-1),
+		columns:      columns,
+		decimalComma: decimalComma,
+	}
+	for i, row := range raw[1:] { // saltar encabezado (fila 0)
+		if isEmptyRow(row) {
+			continue
+		}
+		values := make([]string, len(productImportHeaders))
+		for col, pos := range columns {
+			// GetRows recorta las celdas vacías del final
+			if pos >= 0 && pos < len(row) {
+				values[col] = strings.TrimSpace(row[pos])
+			}
+		}
+		table.rows[i] = values
+	}
+	return table, nil
+}
+
+// mapImportColumns ubica cada columna por el nombre del encabezado, sin
+// distinguir mayúsculas ni acentos.
+func mapImportColumns(header []string) ([]int, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		key := utils.NormalizeName(name)
+		if _, ok := positions[key]; !ok && key != "" {
+			positions[key] = i
+		}
+	}
+
+	columns := make([]int, len(productImportHeaders))
+	for col, name := range productImportHeaders {
+		pos, ok := positions[utils.NormalizeName(name)]
+		if !ok {
+			pos = -1
+		}
+		columns[col] = pos
+	}
+
+	var missing []string
+	for _, col := range productImportRequired {
+		if columns[col] < 0 {
+			missing = append(missing, productImportHeaders[col])
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("faltan columnas obligatorias: %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// productSheetName usa la hoja "Products" de la plantilla o, si no existe, la primera.
+func productSheetName(f *excelize.File) string {
+	if index, err := f.GetSheetIndex(productImportSheet); err == nil && index >= 0 {
+		return productImportSheet
+	}
+	return f.GetSheetName(0)
+}
+
+func (s *productService) validateProductRows(table productTable, options requests.ProductImportOptions, progress ImportProgress) (productImportPlan, error) {
+	categoryMap := map[string]uint{}
+	categories, err := s.categoryOps.FindAll()
+	if err != nil {
+		return productImportPlan{}, fmt.Errorf("error al obtener categorías: %v", err)
+	}
+	for _, c := range categories {
+		if _, ok := categoryMap[utils.NormalizeName(c.Name)]; !ok {
+			categoryMap[utils.NormalizeName(c.Name)] = c.ID
+		}
+	}
+
+	brandMap := map[string]uint{}
+	brands, err := s.brandOps.FindAll()
+	if err != nil {
+		return productImportPlan{}, fmt.Errorf("error al obtener marcas: %v", err)
+	}
+	for _, b := range brands {
+		if _, ok := brandMap[utils.NormalizeName(b.Name)]; !ok {
+			brandMap[utils.NormalizeName(b.Name)] = b.ID
+		}
+	}
+
+	supplierMap := map[string]uint{}
+	suppliers, err := s.supplierOps.FindAll()
+	if err != nil {
+		return productImportPlan{}, fmt.Errorf("error al obtener proveedores: %v", err)
+	}
+	for _, sup := range suppliers {
+		if _, ok := supplierMap[utils.NormalizeName(sup.Name)]; !ok {
+			supplierMap[utils.NormalizeName(sup.Name)] = sup.ID
+		}
+	}
+
+	existing, err := s.loadExistingProducts(table.rows)
+	if err != nil {
+		return productImportPlan{}, fmt.Errorf("error al buscar productos existentes: %v", err)
+	}
+
+	plan := productImportPlan{
+		report: responses.ProductImportReport{
+			Errors:   []responses.ProductImportRowError{},
+			ToCreate: []responses.ProductImportPreview{},
+			ToUpdate: []responses.ProductImportPreview{},
+
+			NewCategories: []string{},
+			NewBrands:     []string{},
+			NewSuppliers:  []string{},
+		},
+	}
+	report := &plan.report
+	seenCodeAndName := map[string]int{}
+	seenSku := map[string]int{}
+	seenProduct := map[uint]int{}
+	pendingCategories := map[string]bool{}
+	pendingBrands := map[string]bool{}
+	pendingSuppliers := map[string]bool{}
+
+	total := len(table.rows)
+	for i, row := range table.rows {
+		rowNum := i + 2 // fila real en el archivo
+		if progress != nil {
+			progress(constants.IMPORT_JOB_PHASE_VALIDATING, i+1, total)
+		}
+
+		if row == nil {
+			continue
+		}
+		report.TotalRows++
+
+		rowErrors := len(report.Errors)
+		addError := func(col int, code constants.ImportErrorCode, message string) {
+			var cell string
+			if table.columns[col] >= 0 {
+				cell, _ = excelize.CoordinatesToCellName(table.columns[col]+1, rowNum)
+			}
+			report.Errors = append(report.Errors, responses.ProductImportRowError{
+				Row:     rowNum,
+				Column:  productImportHeaders[col],
+				Cell:    cell,
+				Code:    string(code),
+				Message: message,
+			})
+		}
+
+		code := row[importColCode]
+		sku := row[importColSku]
+		name := row[importColName]
+		profitMarginStr := row[importColProfitMargin]
+		description := row[importColDescription]
+		categoryName := row[importColCategory]
+		brandName := row[importColBrand]
+		stockStr := row[importColStock]
+		costStr := row[importColCost]
+		supplierName := row[importColSupplier]
+
+		// Validaciones básicas
+		for _, col := range productImportRequired {
+			if row[col] == "" {
+				addError(col, constants.IMPORT_ERROR_REQUIRED, "Campo obligatorio")
+			}
+		}
+
+		for _, limit := range productImportLengths {
+			if len([]rune(row[limit.col])) > limit.max {
+				addError(limit.col, constants.IMPORT_ERROR_TOO_LONG, fmt.Sprintf("Máximo %d caracteres", limit.max))
+			}
+		}
+
+		var profitMargin float64
+		if profitMarginStr != "" {
+			profitMargin, err = parseDecimal(profitMarginStr, table.decimalComma)
+			if err != nil {
+				addError(importColProfitMargin, constants.IMPORT_ERROR_INVALID_NUMBER, "Margen de ganancia inválido")
+			} else if profitMargin < 0 || profitMargin > 100 {
+				addError(importColProfitMargin, constants.IMPORT_ERROR_OUT_OF_RANGE, "Margen de ganancia debe estar entre 0 y 100")
+			}
+		}
+
+		var openingStock int
+		if stockStr != "" {
+			openingStock, err = strconv.Atoi(stockStr)
+			if err != nil {
+				addError(importColStock, constants.IMPORT_ERROR_INVALID_NUMBER, "Stock inicial inválido, debe ser un número entero")
+			} else if openingStock < 0 {
+				addError(importColStock, constants.IMPORT_ERROR_OUT_OF_RANGE, "Stock inicial no puede ser negativo")
+			}
+		}
+
+		var openingCost float64
+		if costStr != "" {
+			openingCost, err = parseDecimal(costStr, table.decimalComma)
+			if err != nil {
+				addError(importColCost, constants.IMPORT_ERROR_INVALID_NUMBER, "Costo inválido")
+			} else if openingCost < 0 {
+				addError(importColCost, constants.IMPORT_ERROR_OUT_OF_RANGE, "Costo no puede ser negativo")
+			}
+		}
+
+		if code != "" && name != "" {
+			key := code + "\x00" + name
+			if previous, ok := seenCodeAndName[key]; ok {
+				addError(importColCode, constants.IMPORT_ERROR_DUPLICATE, fmt.Sprintf("Código y nombre repetidos en la fila %d", previous))
+			}
+			seenCodeAndName[key] = rowNum
+		}
+		if sku != "" {
+			if previous, ok := seenSku[sku]; ok {
+				addError(importColSku, constants.IMPORT_ERROR_DUPLICATE, fmt.Sprintf("SKU repetido en la fila %d", previous))
+			}
+			seenSku[sku] = rowNum
+		}
+
+		// En modo upsert una fila que coincide por SKU o código actualiza ese producto
+		var match *models.Product
+		if options.IsUpsert() && code != "" {
+			var col int
+			match, col, err = existing.match(code, sku)
+			if err != nil {
+				addError(col, constants.IMPORT_ERROR_AMBIGUOUS_MATCH, err.Error())
+			} else if match != nil {
+				if previous, ok := seenProduct[match.ID]; ok {
+					addError(col, constants.IMPORT_ERROR_DUPLICATE, fmt.Sprintf("El producto ya se actualiza en la fila %d", previous))
+				}
+				seenProduct[match.ID] = rowNum
+			}
+		} else if code != "" && name != "" {
+			if existing.hasCodeAndName(code, name) {
+				addError(importColCode, constants.IMPORT_ERROR_DUPLICATE, fmt.Sprintf("Producto con código '%s' y nombre '%s' ya existe", code, name))
+			}
+			if sku != "" && len(existing.bySku[sku]) > 0 {
+				addError(importColSku, constants.IMPORT_ERROR_DUPLICATE, fmt.Sprintf("SKU '%s' ya existe", sku))
+			}
+		}
+
+		var categoryKey, brandKey string
+		categoryID, ok := categoryMap[utils.NormalizeName(categoryName)]
+		if !ok && categoryName != "" {
+			if options.CreateMissing && len([]rune(categoryName)) <= 65 {
+				categoryKey = utils.NormalizeName(categoryName)
+				if !pendingCategories[categoryKey] {
+					pendingCategories[categoryKey] = true
+					plan.newCategories = append(plan.newCategories, categoryName)
+				}
+			} else {
+				addError(importColCategory, constants.IMPORT_ERROR_CATEGORY_NOT_FOUND, fmt.Sprintf("Categoría '%s' no encontrada", categoryName))
+			}
+		}
+
+		brandID, ok := brandMap[utils.NormalizeName(brandName)]
+		if !ok && brandName != "" {
+			if options.CreateMissing && len([]rune(brandName)) <= 65 {
+				brandKey = utils.NormalizeName(brandName)
+				if !pendingBrands[brandKey] {
+					pendingBrands[brandKey] = true
+					plan.newBrands = append(plan.newBrands, brandName)
+				}
+			} else {
+				addError(importColBrand, constants.IMPORT_ERROR_BRAND_NOT_FOUND, fmt.Sprintf("Marca '%s' no encontrada", brandName))
+			}
+		}
+
+		// El saldo inicial solo se carga en productos nuevos, así una planilla
+		// exportada puede volver a importarse sin duplicar el stock.
+		var supplierKey string
+		var supplierID uint
+		if match == nil && openingStock > 0 {
+			if costStr == "" {
+				addError(importColCost, constants.IMPORT_ERROR_REQUIRED, "El costo es obligatorio si se informa stock inicial")
+			}
+			if supplierName == "" {
+				addError(importColSupplier, constants.IMPORT_ERROR_REQUIRED, "El proveedor es obligatorio si se informa stock inicial")
+			} else if id, ok := supplierMap[utils.NormalizeName(supplierName)]; ok {
+				supplierID = id
+			} else if options.CreateMissing && len([]rune(supplierName)) <= 65 {
+				supplierKey = utils.NormalizeName(supplierName)
+				if !pendingSuppliers[supplierKey] {
+					pendingSuppliers[supplierKey] = true
+					plan.newSuppliers = append(plan.newSuppliers, supplierName)
+				}
+			} else {
+				addError(importColSupplier, constants.IMPORT_ERROR_SUPPLIER_NOT_FOUND, fmt.Sprintf("Proveedor '%s' no encontrado", supplierName))
+			}
+		}
+
+		if len(report.Errors) > rowErrors {
+			report.Failed++
+			continue
+		}
+
+		preview := responses.ProductImportPreview{
+			Row:          rowNum,
+			Code:         code,
+			Sku:          sku,
+			Name:         name,
+			ProfitMargin: profitMargin,
+			Description:  description,
+			CategoryName: categoryName,
+			BrandName:    brandName,
+		}
+
+		if match != nil {
+			updated := *match
+			updated.Name = name
+			updated.ProfitMargin = profitMargin
+			updated.Description = description
+			updated.CategoryID = categoryID
+			updated.BrandID = brandID
+			// En las variantes se ignoran margen, categoría y marca: los hereda del padre
+			if match.IsVariant() {
+				updated.ProfitMargin = match.ProfitMargin
+				updated.CategoryID = match.CategoryID
+				updated.BrandID = match.BrandID
+				categoryKey, brandKey = "", ""
+			}
+			if categoryKey == "" && brandKey == "" && !catalogFieldsChanged(*match, updated) {
+				report.Unchanged++
+				continue
+			}
+			preview.ID = match.ID
+			plan.toUpdate = append(plan.toUpdate, plannedProduct{product: updated, categoryKey: categoryKey, brandKey: brandKey})
+			report.ToUpdate = append(report.ToUpdate, preview)
+			continue
+		}
+
+		plan.toCreate = append(plan.toCreate, plannedProduct{
+			product: models.Product{
+				Code:         code,
+				Sku:          sku,
+				Name:         name,
+				ProfitMargin: profitMargin,
+				Description:  description,
+				CategoryID:   categoryID,
+				BrandID:      brandID,
+			},
+			categoryKey:  categoryKey,
+			brandKey:     brandKey,
+			supplierKey:  supplierKey,
+			supplierID:   supplierID,
+			openingStock: openingStock,
+			openingCost:  openingCost,
+		})
+		if openingStock > 0 {
+			preview.OpeningStock = openingStock
+			preview.OpeningCost = openingCost
+			preview.SupplierName = supplierName
+		}
+		report.ToCreate = append(report.ToCreate, preview)
+	}
+
+	report.NewCategories = append(report.NewCategories, plan.newCategories...)
+	report.NewBrands = append(report.NewBrands, plan.newBrands...)
+	report.NewSuppliers = append(report.NewSuppliers, plan.newSuppliers...)
+
+	return plan, nil
+}
+
+// existingProducts indexa por código y por SKU los productos ya guardados que
+// aparecen en la planilla, para no consultar la base fila por fila.
+type existingProducts struct {
+	byCode map[string][]models.Product
+	bySku  map[string][]models.Product
+}
+
+func (s *productService) loadExistingProducts(rows [][]string) (existingProducts, error) {
+	var codes, skus []string
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if row[importColCode] != "" {
+			codes = append(codes, row[importColCode])
+		}
+		if row[importColSku] != "" {
+			skus = append(skus, row[importColSku])
+		}
+	}
+
+	index := existingProducts{
+		byCode: map[string][]models.Product{},
+		bySku:  map[string][]models.Product{},
+	}
+	products, err := s.productRepo.FindByCodesOrSkus(codes, skus)
+	if err != nil {
+		return index, err
+	}
+	for _, product := range products {
+		index.byCode[product.Code] = append(index.byCode[product.Code], product)
+		if product.Sku != "" {
+			index.bySku[product.Sku] = append(index.bySku[product.Sku], product)
+		}
+	}
+	return index, nil
+}
+
+func (e existingProducts) hasCodeAndName(code, name string) bool {
+	for _, product := range e.byCode[code] {
+		if product.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// match busca primero por SKU y después por código. Devuelve la columna que
+// produjo la coincidencia para poder señalarla si es ambigua.
+func (e existingProducts) match(code, sku string) (*models.Product, int, error) {
+	if sku != "" {
+		matches := e.bySku[sku]
+		if len(matches) > 1 {
+			return nil, importColSku, fmt.Errorf("Hay %d productos con el SKU '%s'", len(matches), sku)
+		}
+		if len(matches) == 1 {
+			return &matches[0], importColSku, nil
+		}
+	}
+
+	matches := e.byCode[code]
+	if len(matches) > 1 {
+		return nil, importColCode, fmt.Errorf("Hay %d productos con el código '%s', indicá el SKU", len(matches), code)
+	}
+	if len(matches) == 1 {
+		return &matches[0], importColCode, nil
+	}
+	return nil, importColCode, nil
+}
+
+func catalogFieldsChanged(before, after models.Product) bool {
+	return before.Name != after.Name ||
+		before.ProfitMargin != after.ProfitMargin ||
+		before.Description != after.Description ||
+		before.CategoryID != after.CategoryID ||
+		before.BrandID != after.BrandID
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"libreria/common"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDryRunDB arma las consultas sin ejecutarlas, así que se comporta como una
+// base vacía: no hace falta un Postgres para probar los servicios.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("no se pudo abrir la base de prueba: %v", err)
+	}
+	return db
+}
+
+func TestMapImportColumns(t *testing.T) {
+	// Otro orden, sin acentos, en minúsculas y con las columnas informativas de la exportación
+	header := []string{"nombre", "Precio", "codigo", " Categoria ", "MARCA", "Margen de ganancia (%)", "proveedor"}
+
+	columns, err := mapImportColumns(header)
+	if err != nil {
+		t.Fatalf("mapImportColumns: %v", err)
+	}
+
+	expected := map[int]int{
+		importColCode:         2,
+		importColSku:          -1,
+		importColName:         0,
+		importColProfitMargin: 5,
+		importColDescription:  -1,
+		importColCategory:     3,
+		importColBrand:        4,
+		importColStock:        -1,
+		importColCost:         -1,
+		importColSupplier:     6,
+	}
+	for col, pos := range expected {
+		if columns[col] != pos {
+			t.Errorf("columna %s en la posición %d, se esperaba %d", productImportHeaders[col], columns[col], pos)
+		}
+	}
+}
+
+func TestMapImportColumnsMissingRequired(t *testing.T) {
+	_, err := mapImportColumns([]string{"CÓDIGO", "NOMBRE", "CATEGORÍA"})
+	if err == nil {
+		t.Fatal("se esperaba un error por las columnas obligatorias que faltan")
+	}
+	for _, name := range []string{"MARGEN DE GANANCIA (%)", "MARCA"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("el error %q no menciona la columna %s", err, name)
+		}
+	}
+}
+
+func TestNewProductTable(t *testing.T) {
+	raw := [][]string{
+		{"MARCA", "NOMBRE", "CÓDIGO", "CATEGORÍA", "MARGEN DE GANANCIA (%)"},
+		{" Bic ", "Lapicera", "A1", "Lapiceras", "25"},
+		{"", " ", ""},
+		{"Rivadavia", "Cuaderno", "B2"}, // GetRows recorta las celdas vacías del final
+	}
+
+	table, err := newProductTable(raw, false)
+	if err != nil {
+		t.Fatalf("newProductTable: %v", err)
+	}
+	if len(table.rows) != 3 {
+		t.Fatalf("%d filas, se esperaban 3", len(table.rows))
+	}
+
+	first := table.rows[0]
+	if first[importColCode] != "A1" || first[importColName] != "Lapicera" || first[importColBrand] != "Bic" || first[importColProfitMargin] != "25" {
+		t.Errorf("primera fila = %q, los valores no quedaron en su columna", first)
+	}
+	if table.rows[1] != nil {
+		t.Errorf("la fila vacía tiene que quedar en nil, quedó %q", table.rows[1])
+	}
+	if last := table.rows[2]; last[importColCategory] != "" || last[importColCode] != "B2" {
+		t.Errorf("última fila = %q, se esperaba la categoría vacía", last)
+	}
+
+	if _, err := newProductTable(raw[:1], false); err == nil {
+		t.Error("un archivo con solo el encabezado tiene que dar error")
+	}
+}
+
+func TestImportProductsDryRunReport(t *testing.T) {
+	db := newDryRunDB(t)
+	s := NewProductService(db, repositories.NewProductRepository(db),
+		common.NewGormOperations[models.Category](db),
+		common.NewGormOperations[models.Brand](db),
+		common.NewGormOperations[models.Supplier](db),
+		NewPricingService(db))
+
+	csv := strings.Join([]string{
+		"Nombre;codigo;Categoria;MARCA;margen de ganancia (%);Stock inicial;Costo;Proveedor;PRECIO",
+		"Lapicera azul;A1;Lapiceras;Bic;25,5;10;1.150,75;Distribuidora Norte;999",
+		";;;;;;;;",
+		"Cuaderno;B2;lapiceras;Rivadavia;abc;;;;",
+		"Goma;C3;Gomas;Staedtler;10;5;;;",
+		"Lapicera azul;A1;Lapiceras;Bic;30;;;;",
+	}, "\n")
+	options := requests.ProductImportOptions{
+		DryRun:        true,
+		CreateMissing: true,
+		Format:        "csv",
+		CSVOptions:    requests.CSVOptions{Delimiter: "semicolon", DecimalComma: true},
+	}
+
+	var phases []constants.ImportJobPhase
+	report, err := s.ImportProducts(strings.NewReader(csv), options, func(phase constants.ImportJobPhase, processed, total int) {
+		phases = append(phases, phase)
+	})
+	if err != nil {
+		t.Fatalf("ImportProducts: %v", err)
+	}
+
+	if !report.DryRun || report.Created != 0 || report.Updated != 0 {
+		t.Errorf("reporte = dry_run %v, created %d, updated %d; una prueba no guarda nada", report.DryRun, report.Created, report.Updated)
+	}
+	if report.TotalRows != 4 || report.Failed != 3 {
+		t.Errorf("reporte = total_rows %d, failed %d; se esperaba 4 y 3", report.TotalRows, report.Failed)
+	}
+
+	expectedErrors := []struct {
+		row        int
+		cell, code string
+	}{
+		{4, "E4", string(constants.IMPORT_ERROR_INVALID_NUMBER)},
+		{5, "G5", string(constants.IMPORT_ERROR_REQUIRED)},
+		{5, "H5", string(constants.IMPORT_ERROR_REQUIRED)},
+		{6, "B6", string(constants.IMPORT_ERROR_DUPLICATE)},
+	}
+	if len(report.Errors) != len(expectedErrors) {
+		t.Fatalf("errores = %+v, se esperaban %d", report.Errors, len(expectedErrors))
+	}
+	for i, want := range expectedErrors {
+		got := report.Errors[i]
+		if got.Row != want.row || got.Cell != want.cell || got.Code != want.code {
+			t.Errorf("error %d = fila %d, celda %s, código %s; se esperaba fila %d, celda %s, código %s",
+				i, got.Row, got.Cell, got.Code, want.row, want.cell, want.code)
+		}
+	}
+
+	if len(report.ToCreate) != 1 {
+		t.Fatalf("to_create = %+v, se esperaba solo la fila 2", report.ToCreate)
+	}
+	preview := report.ToCreate[0]
+	if preview.Row != 2 || preview.Code != "A1" || preview.ProfitMargin != 25.5 || preview.OpeningStock != 10 || preview.OpeningCost != 1150.75 || preview.SupplierName != "Distribuidora Norte" {
+		t.Errorf("to_create[0] = %+v", preview)
+	}
+	if !slices.Equal(report.NewSuppliers, []string{"Distribuidora Norte"}) {
+		t.Errorf("new_suppliers = %q", report.NewSuppliers)
+	}
+	if report.NewCategories[0] != "Lapiceras" || slices.Contains(report.NewCategories, "lapiceras") {
+		t.Errorf("new_categories = %q, la categoría se tiene que proponer una sola vez", report.NewCategories)
+	}
+
+	if slices.Contains(phases, constants.IMPORT_JOB_PHASE_SAVING) {
+		t.Error("una prueba no tiene que pasar a la etapa de guardado")
+	}
+}
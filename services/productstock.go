@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"libreria/constants"
 	"libreria/models"
@@ -22,41 +23,46 @@ func NewProductStockService(db *gorm.DB, stockRepo repositories.ProductStockRepo
 	return &productStockService{db: db, productStockRepo: stockRepo}
 }
 
+// stockUpdateAttempts es cuántas veces se relee el stock cuando otro
+// movimiento lo cambió entre la lectura y la escritura.
+const stockUpdateAttempts = 3
+
+// ApplyMovement no abre una transacción propia: si el repositorio fue creado
+// con una transacción, el cambio queda dentro de ella.
 func (s *productStockService) ApplyMovement(productStock models.ProductStock, movementType int) error {
+	var err error
+	for attempt := 0; attempt < stockUpdateAttempts; attempt++ {
+		err = s.applyMovement(productStock, movementType)
+		if !errors.Is(err, models.ErrVersionConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("el stock del producto %d cambió mientras se registraba el movimiento: %w", productStock.ProductID, err)
+}
 
-	tx := s.db.Begin()
-	stockExist, err := s.productStockRepo.FindByID(productStock.ProductID)
+func (s *productStockService) applyMovement(productStock models.ProductStock, movementType int) error {
+	stockExist, err := s.productStockRepo.FindByProductID(productStock.ProductID)
 	if err != nil && err != gorm.ErrRecordNotFound {
-		tx.Rollback()
 		return err
 	}
 	if err == gorm.ErrRecordNotFound {
-
-		if err := s.productStockRepo.Create(&productStock); err != nil {
-			tx.Rollback()
-			return err
-		}
-
-		tx.Commit()
-		return nil
-	} else {
-		if movementType == int(constants.STOCK_MOVEMENT_TYPE_IN) {
-			stockExist.Quantity += productStock.Quantity
-		}
-
 		if movementType == int(constants.STOCK_MOVEMENT_TYPE_OUT) {
-			stockExist.Quantity -= productStock.Quantity
-		}
-
-		if stockExist.Quantity < 0 {
 			return fmt.Errorf("stock insuficiente para producto %d", productStock.ProductID)
 		}
+		return s.productStockRepo.Create(&productStock)
+	}
 
-		if err := s.productStockRepo.Update(&stockExist); err != nil {
-			tx.Rollback()
-			return err
-		}
-		tx.Commit()
-		return nil
+	if movementType == int(constants.STOCK_MOVEMENT_TYPE_IN) {
+		stockExist.Quantity += productStock.Quantity
 	}
+
+	if movementType == int(constants.STOCK_MOVEMENT_TYPE_OUT) {
+		stockExist.Quantity -= productStock.Quantity
+	}
+
+	if stockExist.Quantity < 0 {
+		return fmt.Errorf("stock insuficiente para producto %d", productStock.ProductID)
+	}
+
+	return s.productStockRepo.Update(&stockExist)
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"libreria/utils"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductSupplierService interface {
+	GetByProduct(productID uint) ([]models.ProductSupplier, error)
+	GetCatalog(supplierID uint) ([]models.ProductSupplier, error)
+	Create(request requests.ProductSupplierRequest) (models.ProductSupplier, error)
+	Update(id string, request requests.ProductSupplierRequest) (models.ProductSupplier, error)
+	Delete(id string) error
+	ReorderSuggestions(options requests.ReorderSuggestionOptions) ([]responses.ReorderSuggestion, error)
+}
+
+type productSupplierService struct {
+	db                  *gorm.DB
+	productSupplierRepo repositories.ProductSupplierRepository
+}
+
+// Valores por defecto de las sugerencias de reposición
+const (
+	defaultReorderHistoryDays  = 30
+	defaultReorderCoverageDays = 15
+)
+
+func NewProductSupplierService(db *gorm.DB, productSupplierRepo repositories.ProductSupplierRepository) ProductSupplierService {
+	return &productSupplierService{
+		db:                  db,
+		productSupplierRepo: productSupplierRepo,
+	}
+}
+
+func (s *productSupplierService) GetByProduct(productID uint) ([]models.ProductSupplier, error) {
+	return s.productSupplierRepo.FindByProduct(productID)
+}
+
+func (s *productSupplierService) GetCatalog(supplierID uint) ([]models.ProductSupplier, error) {
+	return s.productSupplierRepo.FindCatalog(supplierID)
+}
+
+func (s *productSupplierService) Create(request requests.ProductSupplierRequest) (models.ProductSupplier, error) {
+	if err := request.Validate(s.db); err != nil {
+		return models.ProductSupplier{}, err
+	}
+
+	productSupplier, err := request.ToModel()
+	if err != nil {
+		return models.ProductSupplier{}, err
+	}
+
+	if err := s.save(&productSupplier); err != nil {
+		return models.ProductSupplier{}, err
+	}
+	return s.productSupplierRepo.FindByID(fmt.Sprint(productSupplier.ID))
+}
+
+func (s *productSupplierService) Update(id string, request requests.ProductSupplierRequest) (models.ProductSupplier, error) {
+	existing, err := s.productSupplierRepo.FindByID(id)
+	if err != nil {
+		return models.ProductSupplier{}, fmt.Errorf("relación producto-proveedor con ID %s no encontrada", id)
+	}
+	if err := request.ValidateUpdate(s.db, existing); err != nil {
+		return models.ProductSupplier{}, err
+	}
+
+	productSupplier, err := request.UpdateModel(existing)
+	if err != nil {
+		return models.ProductSupplier{}, err
+	}
+
+	if err := s.save(&productSupplier); err != nil {
+		return models.ProductSupplier{}, err
+	}
+	return s.productSupplierRepo.FindByID(id)
+}
+
+// save guarda la relación y, si quedó como preferida, desmarca a los demás
+// proveedores del producto.
+func (s *productSupplierService) save(productSupplier *models.ProductSupplier) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		productSupplierRepo := repositories.NewProductSupplierRepository(tx)
+		if err := productSupplierRepo.Save(productSupplier); err != nil {
+			return err
+		}
+		if productSupplier.Preferred {
+			return productSupplierRepo.ClearPreferred(productSupplier.ProductID, productSupplier.ID)
+		}
+		return nil
+	})
+}
+
+func (s *productSupplierService) Delete(id string) error {
+	productSupplier, err := s.productSupplierRepo.FindByID(id)
+	if err != nil {
+		return fmt.Errorf("relación producto-proveedor con ID %s no encontrada", id)
+	}
+	return s.productSupplierRepo.Delete(&productSupplier)
+}
+
+// ReorderSuggestions calcula cuánto pedir de cada producto para cubrir el
+// plazo de entrega más los días de cobertura, según el promedio de ventas
+// diarias. Se usa el proveedor preferido o, si no hay, el de menor costo, y
+// la cantidad se redondea al bulto.
+func (s *productSupplierService) ReorderSuggestions(options requests.ReorderSuggestionOptions) ([]responses.ReorderSuggestion, error) {
+	historyDays := options.HistoryDays
+	if historyDays == 0 {
+		historyDays = defaultReorderHistoryDays
+	}
+	coverageDays := options.CoverageDays
+	if coverageDays == 0 {
+		coverageDays = defaultReorderCoverageDays
+	}
+
+	productSuppliers, err := s.productSupplierRepo.FindForReorder(options.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	best := map[uint]models.ProductSupplier{}
+	var productIDs []uint
+	for _, candidate := range productSuppliers {
+		current, ok := best[candidate.ProductID]
+		if !ok {
+			productIDs = append(productIDs, candidate.ProductID)
+			best[candidate.ProductID] = candidate
+			continue
+		}
+		if preferredSupplier(candidate, current) {
+			best[candidate.ProductID] = candidate
+		}
+	}
+	if len(productIDs) == 0 {
+		return []responses.ReorderSuggestion{}, nil
+	}
+
+	stocks, err := s.productSupplierRepo.StockQuantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	sold, err := s.productSupplierRepo.SoldQuantities(productIDs, time.Now().AddDate(0, 0, -historyDays))
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []responses.ReorderSuggestion{}
+	for _, productID := range productIDs {
+		productSupplier := best[productID]
+		dailySales := float64(sold[productID]) / float64(historyDays)
+		target := int(math.Ceil(dailySales * float64(productSupplier.LeadTimeDays+coverageDays)))
+		stock := stocks[productID]
+		if stock >= target {
+			continue
+		}
+
+		packSize := max(productSupplier.PackSize, 1)
+		quantity := (target - stock + packSize - 1) / packSize * packSize
+
+		suggestions = append(suggestions, responses.ReorderSuggestion{
+			ProductID:     productID,
+			Code:          productSupplier.Product.Code,
+			Name:          productSupplier.Product.Name,
+			Stock:         stock,
+			DailySales:    math.Round(dailySales*100) / 100,
+			SupplierID:    productSupplier.SupplierID,
+			SupplierName:  productSupplier.Supplier.Name,
+			SupplierCode:  productSupplier.SupplierCode,
+			PackSize:      packSize,
+			LeadTimeDays:  productSupplier.LeadTimeDays,
+			LastCost:      productSupplier.LastCost,
+			Quantity:      quantity,
+			EstimatedCost: utils.RoundMoney(float64(quantity) * productSupplier.LastCost),
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	return suggestions, nil
+}
+
+// preferredSupplier indica si candidate es mejor opción de compra que current.
+func preferredSupplier(candidate, current models.ProductSupplier) bool {
+	if candidate.Preferred != current.Preferred {
+		return candidate.Preferred
+	}
+	if candidate.LastCost == 0 || current.LastCost == 0 {
+		return current.LastCost == 0 && candidate.LastCost > 0
+	}
+	return candidate.LastCost < current.LastCost
+}
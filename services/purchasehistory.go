@@ -1,17 +1,18 @@
 package services
 
 import (
+	"errors"
 	"libreria/constants"
 	"libreria/models"
 	"libreria/repositories"
 	"libreria/requests"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PurchaseHistoryService interface {
 	CreatePurchase(request requests.PurchaseHistoryRequest) (models.PurchaseHistory, error)
-	DeletePurchase(id uint64) error
 }
 
 type purchaseHistoryService struct {
@@ -44,39 +45,36 @@ func (s *purchaseHistoryService) CreatePurchase(request requests.PurchaseHistory
 		return models.PurchaseHistory{}, err
 	}
 
-	tx := s.db.Begin()
-	if err := s.purchaseRepo.Create(&purchase); err != nil {
-		tx.Rollback()
-		return models.PurchaseHistory{}, err
-	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repositories.NewPurchaseHistoryRepository(tx).Create(&purchase); err != nil {
+			return err
+		}
+
+		if err := applyMovementFlowTx(tx, request.ProductID, request.Quantity, constants.STOCK_MOVEMENT_TYPE_IN, purchase.ID, "Nueva compra"); err != nil {
+			return err
+		}
 
-	if err := applyMovementFlow(s.productStockService, s.stockMovementService, request.ProductID, request.Quantity, constants.STOCK_MOVEMENT_TYPE_IN, purchase.ID, "Nueva compra"); err != nil {
-		tx.Rollback()
+		return updateLastCost(tx, purchase)
+	})
+	if err != nil {
 		return models.PurchaseHistory{}, err
 	}
-
-	tx.Commit()
 	return purchase, nil
 }
 
-func (s *purchaseHistoryService) DeletePurchase(id uint64) error {
-	tx := s.db.Begin()
-	purchase, err := s.purchaseRepo.FindByID(id)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := applyMovementFlow(s.productStockService, s.stockMovementService, purchase.ProductID, purchase.Quantity, constants.STOCK_MOVEMENT_TYPE_OUT, purchase.ID, "Devolución de compra"); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := s.purchaseRepo.Delete(id); err != nil {
-		tx.Rollback()
+// updateLastCost registra el costo de la compra como el último del proveedor
+// para ese producto, creando la relación si todavía no existía.
+func updateLastCost(tx *gorm.DB, purchase models.PurchaseHistory) error {
+	productSupplierRepo := repositories.NewProductSupplierRepository(tx)
+	productSupplier, err := productSupplierRepo.FindByProductAndSupplier(purchase.ProductID, purchase.SupplierID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	now := time.Now()
+	productSupplier.ProductID = purchase.ProductID
+	productSupplier.SupplierID = purchase.SupplierID
+	productSupplier.LastCost = purchase.Cost
+	productSupplier.LastCostAt = &now
+	return productSupplierRepo.Save(&productSupplier)
 }
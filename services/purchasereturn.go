@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseReturnService interface {
+	CreateReturn(request requests.PurchaseReturnRequest) (models.PurchaseReturn, error)
+	GetAll() ([]models.PurchaseReturn, error)
+	GetByID(id string) (models.PurchaseReturn, error)
+	GetSupplierAccount(supplierID uint) (responses.SupplierAccountResponse, error)
+}
+
+type purchaseReturnService struct {
+	db                  *gorm.DB
+	purchaseReturnRepo  repositories.PurchaseReturnRepository
+	supplierAccountRepo repositories.SupplierAccountRepository
+}
+
+func NewPurchaseReturnService(db *gorm.DB, purchaseReturnRepo repositories.PurchaseReturnRepository, supplierAccountRepo repositories.SupplierAccountRepository) PurchaseReturnService {
+	return &purchaseReturnService{
+		db:                  db,
+		purchaseReturnRepo:  purchaseReturnRepo,
+		supplierAccountRepo: supplierAccountRepo,
+	}
+}
+
+func (s *purchaseReturnService) CreateReturn(request requests.PurchaseReturnRequest) (models.PurchaseReturn, error) {
+	if err := request.Validate(s.db); err != nil {
+		return models.PurchaseReturn{}, err
+	}
+
+	purchaseReturn, err := request.ToModel()
+	if err != nil {
+		return models.PurchaseReturn{}, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseReturnRepo := repositories.NewPurchaseReturnRepository(tx)
+
+		// Las compras originales no se modifican: solo se leen para valorizar la devolución
+		for i, item := range purchaseReturn.Items {
+			var purchase models.PurchaseHistory
+			if err := tx.First(&purchase, item.PurchaseHistoryID).Error; err != nil {
+				return fmt.Errorf("compra con ID %d no encontrada", item.PurchaseHistoryID)
+			}
+
+			returned, err := purchaseReturnRepo.ReturnedQuantity(purchase.ID)
+			if err != nil {
+				return err
+			}
+			if available := purchase.Quantity - returned; item.Quantity > available {
+				return fmt.Errorf("la compra %d solo admite devolver %d unidades", purchase.ID, available)
+			}
+
+			purchaseReturn.SupplierID = purchase.SupplierID
+			purchaseReturn.Items[i].ProductID = purchase.ProductID
+			purchaseReturn.Items[i].UnitCost = purchase.Cost
+			purchaseReturn.Items[i].Subtotal = purchase.Cost * float64(item.Quantity)
+			purchaseReturn.Total += purchaseReturn.Items[i].Subtotal
+		}
+
+		if err := purchaseReturnRepo.Create(&purchaseReturn); err != nil {
+			return err
+		}
+
+		// Si parte de las unidades ya se vendió, el movimiento falla por stock insuficiente
+		for _, item := range purchaseReturn.Items {
+			if err := applyMovementFlowTx(tx, item.ProductID, item.Quantity, constants.STOCK_MOVEMENT_TYPE_OUT, purchaseReturn.ID, "Devolución a proveedor"); err != nil {
+				return err
+			}
+		}
+
+		movement := models.SupplierAccountMovement{
+			SupplierID:   purchaseReturn.SupplierID,
+			Amount:       purchaseReturn.Total,
+			MovementType: int(constants.ACCOUNT_MOVEMENT_TYPE_CREDIT),
+			ReferenceID:  &purchaseReturn.ID,
+			Note:         "Nota de crédito por devolución a proveedor",
+		}
+		return repositories.NewSupplierAccountRepository(tx).Create(&movement)
+	})
+	if err != nil {
+		return models.PurchaseReturn{}, err
+	}
+
+	return s.purchaseReturnRepo.FindByID(strconv.FormatUint(uint64(purchaseReturn.ID), 10))
+}
+
+func (s *purchaseReturnService) GetAll() ([]models.PurchaseReturn, error) {
+	return s.purchaseReturnRepo.FindAll()
+}
+
+func (s *purchaseReturnService) GetByID(id string) (models.PurchaseReturn, error) {
+	return s.purchaseReturnRepo.FindByID(id)
+}
+
+func (s *purchaseReturnService) GetSupplierAccount(supplierID uint) (responses.SupplierAccountResponse, error) {
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, supplierID).Error; err != nil {
+		return responses.SupplierAccountResponse{}, fmt.Errorf("proveedor con ID %d no encontrado", supplierID)
+	}
+
+	movements, err := s.supplierAccountRepo.FindBySupplier(supplierID)
+	if err != nil {
+		return responses.SupplierAccountResponse{}, err
+	}
+
+	balance, err := s.supplierAccountRepo.Balance(supplierID)
+	if err != nil {
+		return responses.SupplierAccountResponse{}, err
+	}
+
+	return responses.SupplierAccountResponse{
+		SupplierID: supplierID,
+		Balance:    balance,
+		Movements:  movements,
+	}, nil
+}
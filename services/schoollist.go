@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type SchoolListService interface {
+	Create(request requests.SchoolListRequest) (models.SchoolList, error)
+	GetByID(id string) (models.SchoolList, error)
+	Update(id string, request requests.SchoolListRequest) (models.SchoolList, error)
+	MapLine(listID, lineID string, request requests.SchoolListLineMappingRequest) (models.SchoolListLine, error)
+	Generate(id string, request requests.SchoolListGenerateRequest) (responses.SchoolListResult, error)
+}
+
+type schoolListService struct {
+	db             *gorm.DB
+	schoolListRepo repositories.SchoolListRepository
+	pricingService PricingService
+	budgetService  BudgetService
+	sellService    SellHistoryService
+}
+
+func NewSchoolListService(db *gorm.DB, schoolListRepo repositories.SchoolListRepository, pricingService PricingService, budgetService BudgetService, sellService SellHistoryService) SchoolListService {
+	return &schoolListService{
+		db:             db,
+		schoolListRepo: schoolListRepo,
+		pricingService: pricingService,
+		budgetService:  budgetService,
+		sellService:    sellService,
+	}
+}
+
+func (s *schoolListService) Create(request requests.SchoolListRequest) (models.SchoolList, error) {
+	if err := request.Validate(s.db); err != nil {
+		return models.SchoolList{}, err
+	}
+
+	list, err := request.ToModel()
+	if err != nil {
+		return models.SchoolList{}, err
+	}
+
+	if err := s.schoolListRepo.Save(&list); err != nil {
+		return models.SchoolList{}, err
+	}
+	return s.GetByID(strconv.FormatUint(uint64(list.ID), 10))
+}
+
+func (s *schoolListService) GetByID(id string) (models.SchoolList, error) {
+	list, err := s.schoolListRepo.FindByID(id)
+	if err != nil {
+		return models.SchoolList{}, fmt.Errorf("lista con ID %s no encontrada", id)
+	}
+	return list, nil
+}
+
+func (s *schoolListService) Update(id string, request requests.SchoolListRequest) (models.SchoolList, error) {
+	list, err := s.GetByID(id)
+	if err != nil {
+		return models.SchoolList{}, err
+	}
+
+	if err := request.Validate(s.db); err != nil {
+		return models.SchoolList{}, err
+	}
+
+	list, err = request.UpdateModel(list)
+	if err != nil {
+		return models.SchoolList{}, err
+	}
+
+	if err := s.schoolListRepo.Save(&list); err != nil {
+		return models.SchoolList{}, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *schoolListService) MapLine(listID, lineID string, request requests.SchoolListLineMappingRequest) (models.SchoolListLine, error) {
+	line, err := s.schoolListRepo.FindLine(listID, lineID)
+	if err != nil {
+		return models.SchoolListLine{}, fmt.Errorf("línea con ID %s no encontrada en la lista %s", lineID, listID)
+	}
+
+	if err := request.Validate(s.db); err != nil {
+		return models.SchoolListLine{}, err
+	}
+
+	line, err = request.UpdateModel(line)
+	if err != nil {
+		return models.SchoolListLine{}, err
+	}
+
+	if err := s.schoolListRepo.SaveLine(&line); err != nil {
+		return models.SchoolListLine{}, err
+	}
+	return line, nil
+}
+
+// Generate arma un presupuesto o una venta con la lista. Cada línea toma su
+// producto si hay stock o, si está asociada a una categoría, el producto más
+// barato de la categoría con stock suficiente. Las líneas que no se pueden
+// cubrir se informan y quedan fuera.
+func (s *schoolListService) Generate(id string, request requests.SchoolListGenerateRequest) (responses.SchoolListResult, error) {
+	list, err := s.GetByID(id)
+	if err != nil {
+		return responses.SchoolListResult{}, err
+	}
+
+	if err := request.Validate(s.db); err != nil {
+		return responses.SchoolListResult{}, err
+	}
+
+	var productIDs []uint
+	for _, line := range list.Lines {
+		if line.ProductID != nil {
+			productIDs = append(productIDs, *line.ProductID)
+		}
+	}
+	available, err := availableQuantities(s.db, productIDs)
+	if err != nil {
+		return responses.SchoolListResult{}, err
+	}
+
+	// Lo que ya tomaron las líneas anteriores, para no usar dos veces el mismo stock
+	reserved := map[uint]int{}
+
+	result := responses.SchoolListResult{Lines: make([]responses.SchoolListLineResult, 0, len(list.Lines))}
+	var items []requests.PricingLineRequest
+	for _, line := range list.Lines {
+		lineResult := responses.SchoolListLineResult{
+			LineID:   line.ID,
+			Text:     line.Text,
+			Quantity: line.Quantity,
+			Status:   string(constants.SCHOOL_LIST_LINE_OUT_OF_STOCK),
+		}
+
+		var picked *models.Product
+		switch {
+		case line.ProductID != nil:
+			if available[*line.ProductID]-reserved[*line.ProductID] >= line.Quantity {
+				picked = line.Product
+			}
+		case line.CategoryID != nil:
+			picked, err = s.pickFromCategory(*line.CategoryID, line.Quantity, reserved)
+			if err != nil {
+				return responses.SchoolListResult{}, err
+			}
+		default:
+			lineResult.Status = string(constants.SCHOOL_LIST_LINE_UNMAPPED)
+		}
+
+		if picked != nil {
+			reserved[picked.ID] += line.Quantity
+			lineResult.Status = string(constants.SCHOOL_LIST_LINE_MATCHED)
+			lineResult.ProductID = picked.ID
+			lineResult.ProductName = picked.Name
+			items = append(items, requests.PricingLineRequest{ProductID: picked.ID, Quantity: line.Quantity})
+		} else {
+			result.Missing++
+		}
+		result.Lines = append(result.Lines, lineResult)
+	}
+
+	if len(items) == 0 {
+		return responses.SchoolListResult{}, fmt.Errorf("ninguna línea de la lista tiene productos disponibles")
+	}
+
+	// Presupuesto y venta valorizan juntos los mismos items, así que las
+	// promociones sobre el total dan lo mismo en los dos casos
+	if request.Target == string(constants.SCHOOL_LIST_TARGET_BUDGET) {
+		budget, err := s.budgetService.CreateBudget(requests.BudgetRequest{
+			ClientName:  request.ClientName,
+			Description: fmt.Sprintf("Lista de útiles %s - %s (%d)", list.School, list.Grade, list.Year),
+			Items:       items,
+		})
+		if err != nil {
+			return responses.SchoolListResult{}, err
+		}
+		result.Budget = &budget
+		return result, nil
+	}
+
+	// CreateSells valoriza todas las líneas en una sola llamada, como CreateBudget
+	sellRequests := make([]requests.SellHistoryRequest, 0, len(items))
+	for _, item := range items {
+		sellRequests = append(sellRequests, requests.SellHistoryRequest{
+			ProductID:  item.ProductID,
+			CustomerID: request.CustomerID,
+			Quantity:   item.Quantity,
+		})
+	}
+	result.Sells, err = s.sellService.CreateSells(sellRequests)
+	if err != nil {
+		return responses.SchoolListResult{}, err
+	}
+	return result, nil
+}
+
+// pickFromCategory elige el producto más barato de la categoría que todavía
+// tiene stock para la cantidad pedida.
+func (s *schoolListService) pickFromCategory(categoryID uint, quantity int, reserved map[uint]int) (*models.Product, error) {
+	candidates, err := s.schoolListRepo.FindInStockByCategory(categoryID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		productIDs = append(productIDs, candidate.ID)
+	}
+	stocks, err := repositories.NewProductStockRepository(s.db).Quantities(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var picked *models.Product
+	var bestPrice float64
+	for i, candidate := range candidates {
+		if stocks[candidate.ID]-reserved[candidate.ID] < quantity {
+			continue
+		}
+		price, err := s.pricingService.UnitPrice(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if picked == nil || price < bestPrice {
+			picked, bestPrice = &candidates[i], price
+		}
+	}
+	return picked, nil
+}
@@ -6,6 +6,7 @@ import (
 	"libreria/models"
 	"libreria/repositories"
 	"libreria/requests"
+	"libreria/responses"
 	"libreria/utils"
 
 	"gorm.io/gorm"
@@ -13,7 +14,7 @@ import (
 
 type SellHistoryService interface {
 	CreateSell(request requests.SellHistoryRequest) (models.SellHistory, error)
-	DeleteSell(id string) error
+	CreateSells(requests []requests.SellHistoryRequest) ([]models.SellHistory, error)
 }
 
 type sellHistoryService struct {
@@ -37,23 +38,96 @@ func NewSellHistoryService(db *gorm.DB, sellRepo repositories.SellHistoryReposit
 }
 
 func (s *sellHistoryService) CreateSell(request requests.SellHistoryRequest) (models.SellHistory, error) {
-	if err := request.Validate(s.db); err != nil {
+	sells, err := s.CreateSells([]requests.SellHistoryRequest{request})
+	if err != nil {
 		return models.SellHistory{}, err
 	}
+	return sells[0], nil
+}
 
-	averageCost, stock, err := utils.CalculateAverageCostAndStock(s.db, request.ProductID)
+// CreateSells registra las líneas de una misma venta. Se valorizan todas
+// juntas, como un presupuesto, para que las promociones sobre el total del
+// pedido se evalúen con la venta completa; si alguna falla no se guarda ninguna.
+// Todas las líneas tienen que ser del mismo cliente.
+func (s *sellHistoryService) CreateSells(sellRequests []requests.SellHistoryRequest) ([]models.SellHistory, error) {
+	if len(sellRequests) == 0 {
+		return nil, fmt.Errorf("la venta no tiene productos")
+	}
+	for _, request := range sellRequests[1:] {
+		if request.CustomerID != sellRequests[0].CustomerID {
+			return nil, fmt.Errorf("todas las líneas de una venta tienen que ser del mismo cliente")
+		}
+	}
 
+	sells := make([]models.SellHistory, 0, len(sellRequests))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		lines := make([]requests.PricingLineRequest, 0, len(sellRequests))
+		for _, request := range sellRequests {
+			if err := request.Validate(tx); err != nil {
+				return err
+			}
+			lines = append(lines, requests.PricingLineRequest{ProductID: request.ProductID, Quantity: request.Quantity})
+		}
+
+		// Los precios y el stock se leen dentro de la misma transacción
+		pricing, err := NewPricingService(tx).PriceLines(lines)
+		if err != nil {
+			return err
+		}
+
+		for i, request := range sellRequests {
+			sell, err := s.createSell(tx, request, pricing.Lines[i])
+			if err != nil {
+				return err
+			}
+			sells = append(sells, sell)
+		}
+		return nil
+	})
 	if err != nil {
-		return models.SellHistory{}, err
+		return nil, err
 	}
+	return sells, nil
+}
 
-	if stock < int64(request.Quantity) {
-		return models.SellHistory{}, fmt.Errorf("stock insuficiente para producto %d", request.ProductID)
+// createSell guarda una línea ya valorizada, con su parte de los descuentos,
+// y descuenta el stock.
+func (s *sellHistoryService) createSell(tx *gorm.DB, request requests.SellHistoryRequest, line responses.PricedLine) (models.SellHistory, error) {
+	bundle, err := findBundle(tx, request.ProductID)
+	if err != nil {
+		return models.SellHistory{}, err
 	}
 
-	var product models.Product
-	if err := s.db.First(&product, request.ProductID).Error; err != nil {
-		return models.SellHistory{}, err
+	var averageCost float64
+	var bundleItems []models.SellBundleItem
+	if bundle != nil {
+		// El combo no tiene stock propio: se controla y se descuenta cada componente
+		for _, component := range bundle.Components {
+			componentCost, stock, err := utils.CalculateAverageCostAndStock(tx, component.ProductID)
+			if err != nil {
+				return models.SellHistory{}, err
+			}
+			if stock < int64(component.Quantity*request.Quantity) {
+				return models.SellHistory{}, fmt.Errorf("stock insuficiente de '%s' para armar %d combos", component.Product.Name, request.Quantity)
+			}
+			averageCost += componentCost * float64(component.Quantity)
+			bundleItems = append(bundleItems, models.SellBundleItem{
+				ProductID:   component.ProductID,
+				Quantity:    component.Quantity,
+				AverageCost: componentCost,
+			})
+		}
+	} else {
+		var stock int64
+		averageCost, stock, err = utils.CalculateAverageCostAndStock(tx, request.ProductID)
+
+		if err != nil {
+			return models.SellHistory{}, err
+		}
+
+		if stock < int64(request.Quantity) {
+			return models.SellHistory{}, fmt.Errorf("stock insuficiente para producto %d", request.ProductID)
+		}
 	}
 
 	sell, err := request.ToModel()
@@ -62,41 +136,32 @@ func (s *sellHistoryService) CreateSell(request requests.SellHistoryRequest) (mo
 	}
 
 	sell.AverageCost = averageCost
-	sell.Price = averageCost * (1 + product.ProfitMargin/100)
-
-	tx := s.db.Begin()
-	if err := s.sellRepo.Create(&sell); err != nil {
-		tx.Rollback()
-		return models.SellHistory{}, err
+	sell.BundleItems = bundleItems
+	sell.ListPrice = line.UnitPrice
+	sell.Discount = line.Discount
+	sell.Price = utils.RoundMoney(line.Total / float64(line.Quantity))
+	for _, discount := range line.Discounts {
+		sell.Discounts = append(sell.Discounts, models.SellHistoryDiscount{
+			PromotionID: discount.PromotionID,
+			Name:        discount.Name,
+			Amount:      discount.Amount,
+		})
 	}
 
-	if err := applyMovementFlow(s.productStockService, s.stockMovementService, request.ProductID, request.Quantity, constants.STOCK_MOVEMENT_TYPE_OUT, sell.ID, "Nueva venta"); err != nil {
-		tx.Rollback()
+	if err := repositories.NewSellHistoryRepository(tx).Create(&sell); err != nil {
 		return models.SellHistory{}, err
 	}
 
-	tx.Commit()
-	return sell, nil
-}
-
-func (s *sellHistoryService) DeleteSell(id string) error {
-	tx := s.db.Begin()
-	sell, err := s.sellRepo.FindByID(id)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := applyMovementFlow(s.productStockService, s.stockMovementService, sell.ProductID, sell.Quantity, constants.STOCK_MOVEMENT_TYPE_IN, sell.ID, "Devolución de venta"); err != nil {
-		tx.Rollback()
-		return err
+	if len(sell.BundleItems) == 0 {
+		if err := applyMovementFlowTx(tx, request.ProductID, request.Quantity, constants.STOCK_MOVEMENT_TYPE_OUT, sell.ID, "Nueva venta"); err != nil {
+			return models.SellHistory{}, err
+		}
+		return sell, nil
 	}
-
-	if err := s.sellRepo.Delete(id); err != nil {
-		tx.Rollback()
-		return err
+	for _, item := range sell.BundleItems {
+		if err := applyMovementFlowTx(tx, item.ProductID, item.Quantity*sell.Quantity, constants.STOCK_MOVEMENT_TYPE_OUT, sell.ID, "Nueva venta (combo)"); err != nil {
+			return models.SellHistory{}, err
+		}
 	}
-
-	tx.Commit()
-	return nil
+	return sell, nil
 }
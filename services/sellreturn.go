@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type SellReturnService interface {
+	CreateReturn(request requests.SellReturnRequest) (models.SellReturn, error)
+	GetAll() ([]models.SellReturn, error)
+	GetByID(id string) (models.SellReturn, error)
+	GetCustomerAccount(customerID uint) (responses.CustomerAccountResponse, error)
+}
+
+type sellReturnService struct {
+	db                  *gorm.DB
+	sellReturnRepo      repositories.SellReturnRepository
+	customerAccountRepo repositories.CustomerAccountRepository
+}
+
+func NewSellReturnService(db *gorm.DB, sellReturnRepo repositories.SellReturnRepository, customerAccountRepo repositories.CustomerAccountRepository) SellReturnService {
+	return &sellReturnService{
+		db:                  db,
+		sellReturnRepo:      sellReturnRepo,
+		customerAccountRepo: customerAccountRepo,
+	}
+}
+
+func (s *sellReturnService) CreateReturn(request requests.SellReturnRequest) (models.SellReturn, error) {
+	if err := request.Validate(s.db); err != nil {
+		return models.SellReturn{}, err
+	}
+
+	sellReturn, err := request.ToModel()
+	if err != nil {
+		return models.SellReturn{}, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sellReturnRepo := repositories.NewSellReturnRepository(tx)
+
+		// Las ventas originales no se modifican: solo se leen para valorizar la devolución
+		sells := make([]models.SellHistory, len(sellReturn.Items))
+		for i, item := range sellReturn.Items {
+			sell := &sells[i]
+			if err := tx.First(sell, item.SellHistoryID).Error; err != nil {
+				return fmt.Errorf("venta con ID %d no encontrada", item.SellHistoryID)
+			}
+
+			returned, err := sellReturnRepo.ReturnedQuantity(sell.ID)
+			if err != nil {
+				return err
+			}
+			if available := sell.Quantity - returned; item.Quantity > available {
+				return fmt.Errorf("la venta %d solo admite devolver %d unidades", sell.ID, available)
+			}
+
+			sellReturn.CustomerID = sell.CustomerID
+			sellReturn.Items[i].ProductID = sell.ProductID
+			sellReturn.Items[i].UnitPrice = sell.Price
+			sellReturn.Items[i].Subtotal = sell.Price * float64(item.Quantity)
+			sellReturn.Total += sellReturn.Items[i].Subtotal
+		}
+
+		if err := sellReturnRepo.Create(&sellReturn); err != nil {
+			return err
+		}
+
+		for i, item := range sellReturn.Items {
+			// Un combo vuelve al stock como sus componentes, según cómo se vendió
+			if bundleItems := sells[i].BundleItems; len(bundleItems) > 0 {
+				for _, component := range bundleItems {
+					if err := applyMovementFlowTx(tx, component.ProductID, component.Quantity*item.Quantity, constants.STOCK_MOVEMENT_TYPE_IN, sellReturn.ID, "Devolución de venta (combo)"); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := applyMovementFlowTx(tx, item.ProductID, item.Quantity, constants.STOCK_MOVEMENT_TYPE_IN, sellReturn.ID, "Devolución de venta"); err != nil {
+				return err
+			}
+		}
+
+		if sellReturn.Settlement == string(constants.RETURN_SETTLEMENT_CREDIT) {
+			movement := models.CustomerAccountMovement{
+				CustomerID:   sellReturn.CustomerID,
+				Amount:       sellReturn.Total,
+				MovementType: int(constants.ACCOUNT_MOVEMENT_TYPE_CREDIT),
+				ReferenceID:  &sellReturn.ID,
+				Note:         "Nota de crédito por devolución",
+			}
+			if err := repositories.NewCustomerAccountRepository(tx).Create(&movement); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.SellReturn{}, err
+	}
+
+	return s.sellReturnRepo.FindByID(strconv.FormatUint(uint64(sellReturn.ID), 10))
+}
+
+func (s *sellReturnService) GetAll() ([]models.SellReturn, error) {
+	return s.sellReturnRepo.FindAll()
+}
+
+func (s *sellReturnService) GetByID(id string) (models.SellReturn, error) {
+	return s.sellReturnRepo.FindByID(id)
+}
+
+func (s *sellReturnService) GetCustomerAccount(customerID uint) (responses.CustomerAccountResponse, error) {
+	var customer models.Customer
+	if err := s.db.First(&customer, customerID).Error; err != nil {
+		return responses.CustomerAccountResponse{}, fmt.Errorf("cliente con ID %d no encontrado", customerID)
+	}
+
+	movements, err := s.customerAccountRepo.FindByCustomer(customerID)
+	if err != nil {
+		return responses.CustomerAccountResponse{}, err
+	}
+
+	balance, err := s.customerAccountRepo.Balance(customerID)
+	if err != nil {
+		return responses.CustomerAccountResponse{}, err
+	}
+
+	return responses.CustomerAccountResponse{
+		CustomerID: customerID,
+		Balance:    balance,
+		Movements:  movements,
+	}, nil
+}
@@ -21,13 +21,5 @@ func NewStockMovementService(db *gorm.DB, stockRepo repositories.StockMovementRe
 }
 
 func (s *stockMovementService) ApplyMovement(stockMovement models.StockMovement) error {
-
-	tx := s.db.Begin()
-
-	if err := s.stockMovementRepo.Create(&stockMovement); err != nil {
-		tx.Rollback()
-		return err
-	}
-	tx.Commit()
-	return nil
+	return s.stockMovementRepo.Create(&stockMovement)
 }
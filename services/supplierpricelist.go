@@ -0,0 +1,518 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"libreria/constants"
+	"libreria/models"
+	"libreria/repositories"
+	"libreria/requests"
+	"libreria/responses"
+	"libreria/utils"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+type SupplierPriceListService interface {
+	GetMapping(supplierID uint) (models.SupplierPriceListMapping, error)
+	SaveMapping(supplierID uint, request requests.SupplierPriceListMappingRequest) (models.SupplierPriceListMapping, error)
+	Upload(supplierID uint, fileName string, reader io.Reader) (responses.SupplierPriceListBatchResponse, error)
+	GetBatches(supplierID uint) ([]models.SupplierPriceListBatch, error)
+	GetBatch(id string) (responses.SupplierPriceListBatchResponse, error)
+	Apply(id string, request requests.ApplySupplierPriceListRequest) (responses.SupplierPriceListBatchResponse, error)
+	Discard(id string) (responses.SupplierPriceListBatchResponse, error)
+}
+
+type supplierPriceListService struct {
+	db                  *gorm.DB
+	priceListRepo       repositories.SupplierPriceListRepository
+	productSupplierRepo repositories.ProductSupplierRepository
+	productRepo         repositories.ProductRepository
+}
+
+func NewSupplierPriceListService(db *gorm.DB, priceListRepo repositories.SupplierPriceListRepository, productSupplierRepo repositories.ProductSupplierRepository, productRepo repositories.ProductRepository) SupplierPriceListService {
+	return &supplierPriceListService{
+		db:                  db,
+		priceListRepo:       priceListRepo,
+		productSupplierRepo: productSupplierRepo,
+		productRepo:         productRepo,
+	}
+}
+
+func (s *supplierPriceListService) GetMapping(supplierID uint) (models.SupplierPriceListMapping, error) {
+	mapping, err := s.priceListRepo.FindMapping(supplierID)
+	if err != nil {
+		return models.SupplierPriceListMapping{}, fmt.Errorf("el proveedor %d no tiene configurado el formato de su lista de precios", supplierID)
+	}
+	return mapping, nil
+}
+
+func (s *supplierPriceListService) SaveMapping(supplierID uint, request requests.SupplierPriceListMappingRequest) (models.SupplierPriceListMapping, error) {
+	if err := request.Validate(); err != nil {
+		return models.SupplierPriceListMapping{}, err
+	}
+
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, supplierID).Error; err != nil {
+		return models.SupplierPriceListMapping{}, fmt.Errorf("proveedor con ID %d no encontrado", supplierID)
+	}
+
+	mapping, err := s.priceListRepo.FindMapping(supplierID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.SupplierPriceListMapping{}, err
+	}
+	mapping.SupplierID = supplierID
+	mapping = request.UpdateModel(mapping)
+
+	if err := s.priceListRepo.SaveMapping(&mapping); err != nil {
+		return models.SupplierPriceListMapping{}, err
+	}
+	return mapping, nil
+}
+
+// Upload lee la lista de precios con el formato guardado del proveedor y arma
+// un lote con los cambios de costo para revisar. No modifica ningún costo.
+func (s *supplierPriceListService) Upload(supplierID uint, fileName string, reader io.Reader) (responses.SupplierPriceListBatchResponse, error) {
+	mapping, err := s.GetMapping(supplierID)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	isCSV := strings.EqualFold(filepath.Ext(fileName), ".csv")
+	raw, err := readPriceListRows(reader, mapping, isCSV)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	columns, err := mapPriceListColumns(raw, mapping)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	lines := make([]priceListLine, 0, len(raw))
+	for i := mapping.HeaderRow; i < len(raw); i++ {
+		if isEmptyRow(raw[i]) {
+			continue
+		}
+		lines = append(lines, priceListLine{row: i + 1, values: columns.read(raw[i])})
+	}
+
+	matcher, err := s.newPriceListMatcher(supplierID, lines)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	batch := models.SupplierPriceListBatch{
+		SupplierID: supplierID,
+		FileName:   fileName,
+		Status:     string(constants.PRICE_LIST_BATCH_STATUS_PENDING),
+		Items:      make([]models.SupplierPriceListItem, 0, len(lines)),
+	}
+	decimalComma := isCSV && mapping.DecimalComma
+	for _, line := range lines {
+		batch.Items = append(batch.Items, matcher.item(line, decimalComma))
+	}
+
+	if err := s.priceListRepo.CreateBatch(&batch); err != nil {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("error al guardar la lista de precios: %v", err)
+	}
+	return s.GetBatch(fmt.Sprint(batch.ID))
+}
+
+func (s *supplierPriceListService) GetBatches(supplierID uint) ([]models.SupplierPriceListBatch, error) {
+	return s.priceListRepo.FindBatches(supplierID)
+}
+
+func (s *supplierPriceListService) GetBatch(id string) (responses.SupplierPriceListBatchResponse, error) {
+	batch, err := s.priceListRepo.FindBatch(id)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("lista de precios con ID %s no encontrada", id)
+	}
+
+	counts := map[string]int{}
+	for _, item := range batch.Items {
+		counts[item.Status]++
+	}
+	return responses.SupplierPriceListBatchResponse{SupplierPriceListBatch: batch, Counts: counts}, nil
+}
+
+// Apply actualiza el último costo del proveedor para los ítems elegidos y, si
+// se pide, carga el precio nuevo de cada producto en la lista de precios.
+func (s *supplierPriceListService) Apply(id string, request requests.ApplySupplierPriceListRequest) (responses.SupplierPriceListBatchResponse, error) {
+	batch, err := s.priceListRepo.FindBatch(id)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("lista de precios con ID %s no encontrada", id)
+	}
+	if batch.Status != string(constants.PRICE_LIST_BATCH_STATUS_PENDING) {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("la lista de precios ya fue %s", batchStatusLabel(batch.Status))
+	}
+
+	items, err := selectPriceListItems(batch.Items, request.ItemIDs)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		productSupplierRepo := repositories.NewProductSupplierRepository(tx)
+		appliedIDs := make([]uint, 0, len(items))
+		for _, item := range items {
+			productSupplier, err := productSupplierRepo.FindByProductAndSupplier(*item.ProductID, batch.SupplierID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			productSupplier.ProductID = *item.ProductID
+			productSupplier.SupplierID = batch.SupplierID
+			// El código del proveedor queda registrado para las próximas listas
+			if productSupplier.SupplierCode == "" {
+				productSupplier.SupplierCode = item.SupplierCode
+			}
+			productSupplier.LastCost = item.NewCost
+			productSupplier.LastCostAt = &now
+			if err := productSupplierRepo.Save(&productSupplier); err != nil {
+				return fmt.Errorf("error al actualizar el costo de la fila %d: %v", item.Row, err)
+			}
+
+			if request.Reprice && item.Product != nil {
+				priceList := models.PriceList{
+					ProductID:   *item.ProductID,
+					Price:       utils.RoundMoney(item.NewCost * (1 + item.Product.ProfitMargin/100)),
+					EffectiveAt: now,
+					IsActive:    true,
+				}
+				if err := tx.Create(&priceList).Error; err != nil {
+					return fmt.Errorf("error al actualizar el precio de la fila %d: %v", item.Row, err)
+				}
+			}
+			appliedIDs = append(appliedIDs, item.ID)
+		}
+
+		priceListRepo := repositories.NewSupplierPriceListRepository(tx)
+		if err := priceListRepo.MarkItemsApplied(appliedIDs); err != nil {
+			return err
+		}
+		batch.Status = string(constants.PRICE_LIST_BATCH_STATUS_APPLIED)
+		batch.AppliedAt = &now
+		return priceListRepo.UpdateBatchStatus(&batch)
+	})
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+
+	return s.GetBatch(id)
+}
+
+func (s *supplierPriceListService) Discard(id string) (responses.SupplierPriceListBatchResponse, error) {
+	batch, err := s.priceListRepo.FindBatch(id)
+	if err != nil {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("lista de precios con ID %s no encontrada", id)
+	}
+	if batch.Status != string(constants.PRICE_LIST_BATCH_STATUS_PENDING) {
+		return responses.SupplierPriceListBatchResponse{}, fmt.Errorf("la lista de precios ya fue %s", batchStatusLabel(batch.Status))
+	}
+
+	batch.Status = string(constants.PRICE_LIST_BATCH_STATUS_DISCARDED)
+	if err := s.priceListRepo.UpdateBatchStatus(&batch); err != nil {
+		return responses.SupplierPriceListBatchResponse{}, err
+	}
+	return s.GetBatch(id)
+}
+
+func batchStatusLabel(status string) string {
+	if status == string(constants.PRICE_LIST_BATCH_STATUS_APPLIED) {
+		return "aplicada"
+	}
+	return "descartada"
+}
+
+// selectPriceListItems devuelve los ítems pedidos o, si no se indicó ninguno,
+// todos los que cambian el costo.
+func selectPriceListItems(items []models.SupplierPriceListItem, ids []uint) ([]models.SupplierPriceListItem, error) {
+	applicable := func(item models.SupplierPriceListItem) bool {
+		return item.ProductID != nil &&
+			item.Status != string(constants.PRICE_LIST_ITEM_STATUS_INVALID) &&
+			item.Status != string(constants.PRICE_LIST_ITEM_STATUS_UNMATCHED)
+	}
+
+	var selected []models.SupplierPriceListItem
+	if len(ids) == 0 {
+		for _, item := range items {
+			if item.Status == string(constants.PRICE_LIST_ITEM_STATUS_CHANGED) || item.Status == string(constants.PRICE_LIST_ITEM_STATUS_NEW) {
+				selected = append(selected, item)
+			}
+		}
+	} else {
+		byID := make(map[uint]models.SupplierPriceListItem, len(items))
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+		for _, id := range ids {
+			item, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("el ítem %d no pertenece a esta lista de precios", id)
+			}
+			if !applicable(item) {
+				return nil, fmt.Errorf("el ítem de la fila %d no tiene un producto asociado o un costo válido", item.Row)
+			}
+			selected = append(selected, item)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no hay cambios de costo para aplicar")
+	}
+	return selected, nil
+}
+
+func readPriceListRows(reader io.Reader, mapping models.SupplierPriceListMapping, isCSV bool) ([][]string, error) {
+	if isCSV {
+		return readCSV(reader, requests.CSVOptions{Delimiter: mapping.Delimiter, Encoding: mapping.Encoding})
+	}
+
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el archivo: %v", err)
+	}
+	defer f.Close()
+
+	sheet := mapping.Sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	// Valores sin el formato de la celda, para no recibir "$ 1.234,50"
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la hoja '%s': %v", sheet, err)
+	}
+	return rows, nil
+}
+
+// priceListColumns guarda la posición de cada columna del formato, -1 si no se usa.
+type priceListColumns struct {
+	code, barcode, sku, description, cost int
+}
+
+type priceListValues struct {
+	code, barcode, sku, description, cost string
+}
+
+type priceListLine struct {
+	row    int
+	values priceListValues
+}
+
+func (c priceListColumns) read(row []string) priceListValues {
+	cell := func(pos int) string {
+		if pos < 0 || pos >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[pos])
+	}
+	return priceListValues{
+		code:        cell(c.code),
+		barcode:     cell(c.barcode),
+		sku:         cell(c.sku),
+		description: cell(c.description),
+		cost:        cell(c.cost),
+	}
+}
+
+func mapPriceListColumns(raw [][]string, mapping models.SupplierPriceListMapping) (priceListColumns, error) {
+	if len(raw) < mapping.HeaderRow {
+		return priceListColumns{}, fmt.Errorf("el archivo no tiene la fila de encabezado %d", mapping.HeaderRow)
+	}
+
+	positions := map[string]int{}
+	for i, name := range raw[mapping.HeaderRow-1] {
+		key := utils.NormalizeName(name)
+		if _, ok := positions[key]; !ok && key != "" {
+			positions[key] = i
+		}
+	}
+
+	var missing []string
+	locate := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		pos, ok := positions[utils.NormalizeName(name)]
+		if !ok {
+			missing = append(missing, name)
+			return -1
+		}
+		return pos
+	}
+
+	columns := priceListColumns{
+		code:        locate(mapping.CodeColumn),
+		barcode:     locate(mapping.BarcodeColumn),
+		sku:         locate(mapping.SkuColumn),
+		description: locate(mapping.DescriptionColumn),
+		cost:        locate(mapping.CostColumn),
+	}
+	if len(missing) > 0 {
+		return priceListColumns{}, fmt.Errorf("el archivo no tiene las columnas: %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// priceListMatcher resuelve las filas contra el catálogo con datos cargados de
+// una sola vez: códigos del proveedor, códigos de barras y SKUs.
+type priceListMatcher struct {
+	bySupplierCode map[string][]models.ProductSupplier
+	byProduct      map[uint]models.ProductSupplier
+	byBarcode      map[string]uint
+	bySku          map[string][]models.Product
+}
+
+func (s *supplierPriceListService) newPriceListMatcher(supplierID uint, lines []priceListLine) (priceListMatcher, error) {
+	matcher := priceListMatcher{
+		bySupplierCode: map[string][]models.ProductSupplier{},
+		byProduct:      map[uint]models.ProductSupplier{},
+		byBarcode:      map[string]uint{},
+		bySku:          map[string][]models.Product{},
+	}
+
+	productSuppliers, err := s.productSupplierRepo.FindBySupplier(supplierID)
+	if err != nil {
+		return matcher, err
+	}
+	for _, productSupplier := range productSuppliers {
+		matcher.byProduct[productSupplier.ProductID] = productSupplier
+		if productSupplier.SupplierCode != "" {
+			matcher.bySupplierCode[productSupplier.SupplierCode] = append(matcher.bySupplierCode[productSupplier.SupplierCode], productSupplier)
+		}
+	}
+
+	var barcodes, skus []string
+	for _, line := range lines {
+		if line.values.barcode != "" {
+			barcodes = append(barcodes, utils.BarcodeVariants(line.values.barcode)...)
+		}
+		if line.values.sku != "" {
+			skus = append(skus, line.values.sku)
+		}
+	}
+
+	productBarcodes, err := s.productRepo.FindByBarcodes(barcodes)
+	if err != nil {
+		return matcher, err
+	}
+	for _, barcode := range productBarcodes {
+		matcher.byBarcode[barcode.Code] = barcode.ProductID
+	}
+
+	products, err := s.productRepo.FindByCodesOrSkus(nil, skus)
+	if err != nil {
+		return matcher, err
+	}
+	for _, product := range products {
+		matcher.bySku[product.Sku] = append(matcher.bySku[product.Sku], product)
+	}
+
+	return matcher, nil
+}
+
+func (m priceListMatcher) item(line priceListLine, decimalComma bool) models.SupplierPriceListItem {
+	values := line.values
+	item := models.SupplierPriceListItem{
+		Row:          line.row,
+		SupplierCode: values.code,
+		Barcode:      values.barcode,
+		Sku:          values.sku,
+		Description:  values.description,
+	}
+	invalid := func(message string) models.SupplierPriceListItem {
+		item.Status = string(constants.PRICE_LIST_ITEM_STATUS_INVALID)
+		item.Message = message
+		return item
+	}
+
+	// Se recortan para que entren en las columnas; si el código no entra no se puede usar
+	item.Description = truncate(item.Description, 150)
+	if len([]rune(item.SupplierCode)) > 30 || len([]rune(item.Barcode)) > 14 || len([]rune(item.Sku)) > 20 {
+		item.SupplierCode = truncate(item.SupplierCode, 30)
+		item.Barcode = truncate(item.Barcode, 14)
+		item.Sku = truncate(item.Sku, 20)
+		return invalid("Código demasiado largo")
+	}
+
+	cost, err := parseDecimal(strings.TrimSpace(strings.TrimPrefix(values.cost, "$")), decimalComma)
+	if err != nil {
+		return invalid(fmt.Sprintf("Costo inválido: '%s'", values.cost))
+	}
+	if cost <= 0 {
+		return invalid("El costo debe ser mayor a 0")
+	}
+	item.NewCost = utils.RoundMoney(cost)
+
+	productID, matchedBy, err := m.match(values)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if productID == 0 {
+		item.Status = string(constants.PRICE_LIST_ITEM_STATUS_UNMATCHED)
+		item.Message = "No se encontró un producto para esta fila"
+		return item
+	}
+	item.ProductID = &productID
+	item.MatchedBy = string(matchedBy)
+
+	productSupplier, ok := m.byProduct[productID]
+	switch {
+	case !ok || productSupplier.LastCostAt == nil:
+		item.Status = string(constants.PRICE_LIST_ITEM_STATUS_NEW)
+	case utils.RoundMoney(productSupplier.LastCost) == item.NewCost:
+		item.PreviousCost = &productSupplier.LastCost
+		item.Status = string(constants.PRICE_LIST_ITEM_STATUS_UNCHANGED)
+	default:
+		item.PreviousCost = &productSupplier.LastCost
+		item.Status = string(constants.PRICE_LIST_ITEM_STATUS_CHANGED)
+	}
+	return item
+}
+
+// match prueba por código del proveedor, después por código de barras y por último por SKU.
+func (m priceListMatcher) match(values priceListValues) (uint, constants.PriceListMatch, error) {
+	if values.code != "" {
+		matches := m.bySupplierCode[values.code]
+		if len(matches) > 1 {
+			return 0, "", fmt.Errorf("El código '%s' está asociado a %d productos", values.code, len(matches))
+		}
+		if len(matches) == 1 {
+			return matches[0].ProductID, constants.PRICE_LIST_MATCH_SUPPLIER_CODE, nil
+		}
+	}
+
+	if values.barcode != "" {
+		for _, variant := range utils.BarcodeVariants(values.barcode) {
+			if productID, ok := m.byBarcode[variant]; ok {
+				return productID, constants.PRICE_LIST_MATCH_BARCODE, nil
+			}
+		}
+	}
+
+	if values.sku != "" {
+		matches := m.bySku[values.sku]
+		if len(matches) > 1 {
+			return 0, "", fmt.Errorf("Hay %d productos con el SKU '%s'", len(matches), values.sku)
+		}
+		if len(matches) == 1 {
+			return matches[0].ID, constants.PRICE_LIST_MATCH_SKU, nil
+		}
+	}
+
+	return 0, "", nil
+}
+
+func truncate(value string, max int) string {
+	if runes := []rune(value); len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
+}
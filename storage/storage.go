@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var ErrNotFound = errors.New("archivo no encontrado")
+
+// Storage guarda archivos identificados por una clave con forma de ruta,
+// por ejemplo "products/12/foto.jpg".
+type Storage interface {
+	Put(key string, content []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// NewFromEnv crea el almacenamiento indicado por STORAGE_DRIVER: "local"
+// (por defecto) guarda en STORAGE_PATH y "s3" en un bucket compatible con S3.
+func NewFromEnv() (Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		path := os.Getenv("STORAGE_PATH")
+		if path == "" {
+			path = "assets/uploads"
+		}
+		return NewLocalStorage(path), nil
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER '%s' no soportado", driver)
+	}
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("clave de archivo inválida: '%s'", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("clave de archivo inválida: '%s'", key)
+		}
+	}
+	return nil
+}
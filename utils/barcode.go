@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidateBarcode verifica el largo y el dígito verificador de códigos
+// EAN-13, EAN-8 y UPC-A (todos usan el mismo algoritmo GTIN).
+func ValidateBarcode(code string) error {
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return errors.New("el código de barras debe tener 8, 12 o 13 dígitos")
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return errors.New("el código de barras solo puede contener dígitos")
+		}
+		digit := int(c - '0')
+		// Desde la derecha (sin contar el verificador) los pesos alternan 3 y 1
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	last := code[len(code)-1]
+	if last < '0' || last > '9' {
+		return errors.New("el código de barras solo puede contener dígitos")
+	}
+	expected := (10 - sum%10) % 10
+	if int(last-'0') != expected {
+		return fmt.Errorf("dígito verificador inválido, se esperaba %d", expected)
+	}
+	return nil
+}
+
+// BarcodeVariants devuelve las formas equivalentes de un código: los lectores
+// pueden enviar un UPC-A de 12 dígitos o el mismo código como EAN-13 con un 0 adelante.
+func BarcodeVariants(code string) []string {
+	variants := []string{code}
+	if len(code) == 12 {
+		variants = append(variants, "0"+code)
+	}
+	if len(code) == 13 && code[0] == '0' {
+		variants = append(variants, code[1:])
+	}
+	return variants
+}
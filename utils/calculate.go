@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"fmt"
 	"libreria/models"
+	"math"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -18,29 +22,113 @@ func CalculateAverageCostAndStock(db *gorm.DB, productID uint) (float64, int64,
 	if stock <= 0 {
 		return 0, 0, nil
 	}
-	var purchaseHistories []models.PurchaseHistory
-	if err := db.Where("product_id = ?", productID).Order("created_at asc").Find(&purchaseHistories).Error; err != nil {
+	lots, err := purchaseLots(db, []uint{productID})
+	if err != nil {
 		return 0, 0, err
 	}
 
+	return averageCost(stock, lots), stock, nil
+}
+
+// CalculateAverageCostsAndStocks es CalculateAverageCostAndStock para varios
+// productos con dos consultas en total. Los que no tienen stock no aparecen.
+func CalculateAverageCostsAndStocks(db *gorm.DB, productIDs []uint) (map[uint]float64, map[uint]int64, error) {
+	costs := make(map[uint]float64)
+	stocks := make(map[uint]int64)
+	if len(productIDs) == 0 {
+		return costs, stocks, nil
+	}
+
+	var productStocks []models.ProductStock
+	if err := db.Where("product_id IN ? AND quantity > 0", productIDs).Find(&productStocks).Error; err != nil {
+		return nil, nil, err
+	}
+	inStock := make([]uint, 0, len(productStocks))
+	for _, productStock := range productStocks {
+		stocks[productStock.ProductID] = int64(productStock.Quantity)
+		inStock = append(inStock, productStock.ProductID)
+	}
+	if len(inStock) == 0 {
+		return costs, stocks, nil
+	}
+
+	lots, err := purchaseLots(db, inStock)
+	if err != nil {
+		return nil, nil, err
+	}
+	byProduct := make(map[uint][]purchaseLot, len(inStock))
+	for _, lot := range lots {
+		byProduct[lot.ProductID] = append(byProduct[lot.ProductID], lot)
+	}
+	for productID, stock := range stocks {
+		costs[productID] = averageCost(stock, byProduct[productID])
+	}
+	return costs, stocks, nil
+}
+
+// purchaseLot es una compra con las unidades que quedan de ella después de las
+// devoluciones al proveedor.
+type purchaseLot struct {
+	ProductID uint
+	Cost      float64
+	Quantity  int
+}
+
+// purchaseLots devuelve las compras de los productos, de la más antigua a la
+// más nueva, descontando lo devuelto al proveedor. Las compras no se modifican:
+// lo devuelto se suma desde los ítems de las devoluciones.
+func purchaseLots(db *gorm.DB, productIDs []uint) ([]purchaseLot, error) {
+	var lots []purchaseLot
+	err := db.Model(&models.PurchaseHistory{}).
+		Select("purchase_histories.product_id, purchase_histories.cost, "+
+			"purchase_histories.quantity - COALESCE(SUM(returned.quantity), 0) AS quantity").
+		Joins("LEFT JOIN purchase_return_items returned ON returned.purchase_history_id = purchase_histories.id AND returned.deleted_at IS NULL").
+		Where("purchase_histories.product_id IN ?", productIDs).
+		Group("purchase_histories.id").
+		Order("purchase_histories.product_id, purchase_histories.created_at asc").
+		Scan(&lots).Error
+	return lots, err
+}
+
+// averageCost valoriza el stock con las compras, de la más antigua a la más
+// nueva, y devuelve el costo por unidad.
+func averageCost(stock int64, lots []purchaseLot) float64 {
 	var totalCost float64
 	remaining := stock
-	for _, ph := range purchaseHistories {
+	for _, lot := range lots {
 		if remaining <= 0 {
 			break
 		}
-		quantity := int64(ph.Quantity)
+		quantity := int64(lot.Quantity)
 		if quantity > remaining {
 			quantity = remaining
 		}
-		totalCost += float64(quantity) * ph.Cost
+		totalCost += float64(quantity) * lot.Cost
 		remaining -= quantity
 	}
+	return totalCost / float64(stock)
+}
 
-	if stock == 0 {
-		return 0, 0, nil
+func RoundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// FormatMoney da formato de moneda argentina, ej: $ 1.600,00
+func FormatMoney(value float64) string {
+	cents := int64(math.Round(math.Abs(value) * 100))
+	integer := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
 	}
-	averageCost := totalCost / float64(stock)
 
-	return averageCost, stock, nil
+	sign := ""
+	if value < 0 && cents > 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s$ %s,%02d", sign, grouped.String(), cents%100)
 }
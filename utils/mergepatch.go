@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// MergePatch aplica un JSON merge patch (RFC 7386) sobre document: los campos
+// del patch reemplazan a los del documento, los objetos se combinan campo por
+// campo y un null borra el campo.
+func MergePatch(document, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := decodeNumbers(document, &target); err != nil {
+		return nil, err
+	}
+	if err := decodeNumbers(patch, &changes); err != nil {
+		return nil, err
+	}
+	if _, ok := changes.(map[string]interface{}); !ok {
+		return nil, errors.New("el merge patch debe ser un objeto JSON")
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = map[string]interface{}{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergeValue(result[key], value)
+	}
+	return result
+}
+
+// decodeNumbers conserva los números como vienen para no perder precisión
+// con IDs grandes.
+func decodeNumbers(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Casos del apéndice A de la RFC 7386
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		document, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		merged, err := MergePatch([]byte(c.document), []byte(c.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", c.document, c.patch, err)
+			continue
+		}
+		if !equalJSON(t, merged, []byte(c.expected)) {
+			t.Errorf("MergePatch(%s, %s) = %s, se esperaba %s", c.document, c.patch, merged, c.expected)
+		}
+	}
+}
+
+func TestMergePatchKeepsLargeNumbers(t *testing.T) {
+	merged, err := MergePatch([]byte(`{"id":9007199254740993,"name":"a"}`), []byte(`{"name":"b"}`))
+	if err != nil {
+		t.Fatalf("MergePatch: %v", err)
+	}
+	if string(merged) != `{"id":9007199254740993,"name":"b"}` {
+		t.Errorf("MergePatch = %s, el ID perdió precisión", merged)
+	}
+}
+
+func TestMergePatchRejectsNonObjectPatch(t *testing.T) {
+	for _, patch := range []string{`["a"]`, `"a"`, `null`, `{`} {
+		if _, err := MergePatch([]byte(`{"a":"b"}`), []byte(patch)); err == nil {
+			t.Errorf("MergePatch con %s tenía que dar error", patch)
+		}
+	}
+}
+
+func equalJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var left, right interface{}
+	if err := json.Unmarshal(a, &left); err != nil {
+		t.Fatalf("JSON inválido %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &right); err != nil {
+		t.Fatalf("JSON inválido %s: %v", b, err)
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName deja un nombre listo para comparar: sin tildes, en minúsculas
+// y con un solo espacio entre palabras ("Útil " y "util" dan lo mismo).
+func NormalizeName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, name)
+	if err != nil {
+		result = name
+	}
+	return strings.ToLower(strings.Join(strings.Fields(result), " "))
+}